@@ -0,0 +1,165 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pinealctx/multicall/contract"
+)
+
+// Aggregator is a method of the multicall contract making multicalls.
+type Aggregator int32
+
+const (
+	// Aggregate3 is the aggregate3 method of Multicall3, the default.
+	Aggregate3 Aggregator = iota
+	// TryAggregate is the tryAggregate method of Multicall2 and Multicall3.
+	TryAggregate
+	// Aggregate is the aggregate method of all multicall versions. It reverts
+	// the whole batch if any call fails.
+	Aggregate
+)
+
+func (a Aggregator) String() string {
+	switch a {
+	case Aggregate3:
+		return "aggregate3"
+	case TryAggregate:
+		return "tryAggregate"
+	case Aggregate:
+		return "aggregate"
+	}
+	return fmt.Sprintf("Aggregator(%d)", int32(a))
+}
+
+// Aggregator returns the method Call makes multicalls with, aggregate3
+// unless another one was detected by Autodetect.
+func (caller *Caller) Aggregator() Aggregator {
+	if caller.aggregator == nil {
+		return Aggregate3
+	}
+	return Aggregator(caller.aggregator.Load())
+}
+
+// Autodetect probes the multicall contract with an empty dry call of aggregate3,
+// tryAggregate and aggregate in turn, and makes Call use the first one available,
+// so that Multicall (v1), Multicall2 and Multicall3 deployments are supported
+// transparently. Only a reverting probe moves on to the next method: node errors
+// are retried as configured with WithNodeErrorRetry, then returned, leaving the
+// method unchanged. The detected method is kept by the caller and the callers
+// derived from it with WithContext.
+func (caller *Caller) Autodetect(ctx context.Context) (Aggregator, error) {
+	opts := caller.callOpts(&bind.CallOpts{Context: ctx})
+	probes := []struct {
+		aggregator Aggregator
+		probe      func() error
+	}{
+		{Aggregate3, func() error {
+			_, err := caller.contract.Aggregate3(opts, []contract.Multicall3Call3{})
+			return err
+		}},
+		{TryAggregate, func() error {
+			_, err := caller.contract.TryAggregate(opts, false, []contract.Multicall3Call{})
+			return err
+		}},
+		{Aggregate, func() error {
+			_, err := caller.contract.Aggregate(opts, []contract.Multicall3Call{})
+			return err
+		}},
+	}
+	var errs []error
+	for _, p := range probes {
+		err := caller.retry(opts.Context, p.probe)
+		if err == nil {
+			caller.logf("multicall: detected %s", p.aggregator)
+			caller.aggregator.Store(int32(p.aggregator))
+			return p.aggregator, nil
+		}
+		if opts.Context != nil && opts.Context.Err() != nil {
+			return Aggregate3, opts.Context.Err()
+		}
+		if !IsContractRevert(err) {
+			return Aggregate3, fmt.Errorf("failed to probe %s: %v", p.aggregator, err)
+		}
+		errs = append(errs, fmt.Errorf("%s: %v", p.aggregator, err))
+	}
+	return Aggregate3, fmt.Errorf("no multicall method available at %s: %v", caller.address, errors.Join(errs...))
+}
+
+// callTryAggregate makes the multicall with tryAggregate.
+func (caller *Caller) callTryAggregate(c contract.Interface, opts *bind.CallOpts, requireSuccess bool, calls []*Call) ([]*Call, error) {
+	return caller.callTry(opts, calls, "tryAggregate", func(opts *bind.CallOpts, tryCalls []contract.Multicall3Call) ([]contract.Multicall3Result, error) {
+		return c.TryAggregate(opts, requireSuccess, tryCalls)
+	})
+}
+
+// callTry makes the multicall with a method of the tryAggregate family sent by
+// send. The calls not allowed to fail are checked after the multicall, as these
+// methods only support a single failure mode for all calls.
+func (caller *Caller) callTry(opts *bind.CallOpts, calls []*Call, method string, send func(*bind.CallOpts, []contract.Multicall3Call) ([]contract.Multicall3Result, error)) ([]*Call, error) {
+	opts = caller.callOpts(opts)
+	if err := caller.validate(calls); err != nil {
+		return calls, err
+	}
+	resetCalls(calls)
+	multiCalls, err := packCalls(calls)
+	if err != nil {
+		return calls, err
+	}
+	tryCalls := make([]contract.Multicall3Call, len(multiCalls))
+	var calldataBytes int
+	for i, multiCall := range multiCalls {
+		tryCalls[i] = contract.Multicall3Call{Target: multiCall.Target, CallData: multiCall.CallData}
+		calldataBytes += len(multiCall.CallData)
+	}
+	if err := caller.checkRequestSize(len(tryCalls), method, false, tryCalls); err != nil {
+		return calls, err
+	}
+	caller.observeEncoded(calldataBytes)
+
+	caller.logf("multicall: sending %d calls with %s", len(tryCalls), method)
+	var results []contract.Multicall3Result
+	err = caller.retry(opts.Context, func() (err error) {
+		results, err = send(opts, tryCalls)
+		return err
+	})
+	if err != nil {
+		caller.logf("multicall: failed: %v", err)
+		return calls, multicallError(err)
+	}
+
+	var returnBytes int
+	for _, result := range results {
+		returnBytes += len(result.ReturnData)
+	}
+	caller.observeDecoded(returnBytes)
+
+	if err := unpackResults(calls, results, caller.onResult); err != nil {
+		return calls, err
+	}
+	for i, call := range calls {
+		if call.Failed && !call.CanFail {
+			return calls, fmt.Errorf("call '%s' at index [%d] failed: %s", call.label(), i, call.RevertReason)
+		}
+	}
+	return calls, nil
+}
+
+// CallAt makes multicalls like Call using tryBlockAndAggregate, and returns the
+// number of the block the calls were executed at, e.g. to know which block
+// the results reflect when reading at the latest block.
+func (caller *Caller) CallAt(opts *bind.CallOpts, calls ...*Call) (*big.Int, []*Call, error) {
+	var blockNumber *big.Int
+	_, err := caller.callTry(opts, calls, "tryBlockAndAggregate", func(opts *bind.CallOpts, tryCalls []contract.Multicall3Call) ([]contract.Multicall3Result, error) {
+		result, err := caller.contract.TryBlockAndAggregate(opts, false, tryCalls)
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = result.BlockNumber
+		return result.ReturnData, nil
+	})
+	return blockNumber, calls, err
+}
@@ -0,0 +1,127 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestCallAggregate(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+	balance := new(big.Int)
+	blockNumber, _, err := caller.CallAggregate(BlockCallOpts(big.NewInt(42)), c.NewCall(balance, "balanceOf", ownerAddress))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if blockNumber == nil || blockNumber.Int64() != 42 {
+		t.Fatalf("expected block 42, got %v", blockNumber)
+	}
+	if balance.Int64() != 0xbb {
+		t.Fatalf("unexpected balance %s", balance)
+	}
+}
+
+func TestCallAggregateAllowFailure(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+	_, _, err := caller.CallAggregate(nil,
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress),
+		c.NewCall(new(big.Int), "fail").AllowFailure(),
+	)
+	if !errors.Is(err, ErrAllowFailureUnsupported) {
+		t.Fatalf("expected ErrAllowFailureUnsupported, got %v", err)
+	}
+	if chain.calls() != 0 {
+		t.Fatal("expected no eth_call")
+	}
+}
+
+func TestAutodetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		methods []string
+		want    Aggregator
+		// sent is the method of the multicall sent after detection.
+		sent string
+	}{
+		{"multicall3", []string{"aggregate3", "tryAggregate", "aggregate"}, Aggregate3, "aggregate3"},
+		{"multicall2", []string{"tryAggregate", "aggregate"}, TryAggregate, "tryAggregate"},
+		{"multicall", []string{"aggregate"}, Aggregate, "aggregate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mustContract(t, testABI, tokenAddress)
+			chain := newFakeChain(t)
+			chain.methods = tt.methods
+			caller := newTestCaller(t, chain)
+			got, err := caller.Autodetect(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || caller.Aggregator() != tt.want {
+				t.Fatalf("expected %s, got %s and %s", tt.want, got, caller.Aggregator())
+			}
+
+			calls := balanceCalls(c, 3)
+			if _, err := caller.Call(nil, calls...); err != nil {
+				t.Fatal(err)
+			}
+			for i, call := range calls {
+				if call.Outputs.(*big.Int).Int64() != int64(i) {
+					t.Fatalf("call %d: unexpected balance %s", i, call.Outputs)
+				}
+			}
+			if sent := chain.methodsSent(t); sent[len(sent)-1] != tt.sent {
+				t.Fatalf("expected multicall with %s, got %v", tt.sent, sent)
+			}
+		})
+	}
+
+	chain := newFakeChain(t)
+	chain.methods = []string{}
+	caller := newTestCaller(t, chain)
+	if _, err := caller.Autodetect(context.Background()); err == nil {
+		t.Fatal("expected error without any multicall method")
+	}
+	if caller.Aggregator() != Aggregate3 {
+		t.Fatalf("expected default %s, got %s", Aggregate3, caller.Aggregator())
+	}
+}
+
+func TestAutodetectNodeError(t *testing.T) {
+	chain := newFakeChain(t)
+	chain.fail = func(n int, calls []subCall) error {
+		if n == 0 {
+			return errors.New("connection reset by peer")
+		}
+		return nil
+	}
+	caller := newTestCaller(t, chain, WithNodeErrorRetry(3))
+	got, err := caller.Autodetect(context.Background())
+	if err != nil || got != Aggregate3 {
+		t.Fatalf("expected %s after a retry, got %s: %v", Aggregate3, got, err)
+	}
+	if sent := chain.methodsSent(t); len(sent) != 2 || sent[1] != "aggregate3" {
+		t.Fatalf("expected aggregate3 probed twice, got %v", sent)
+	}
+
+	chain = newFakeChain(t)
+	chain.fail = func(n int, calls []subCall) error {
+		return errors.New("connection reset by peer")
+	}
+	caller = newTestCaller(t, chain)
+	if _, err := caller.Autodetect(context.Background()); err == nil || !strings.Contains(err.Error(), "connection reset by peer") {
+		t.Fatalf("expected the node error, got %v", err)
+	}
+	if sent := chain.methodsSent(t); len(sent) != 1 {
+		t.Fatalf("expected no other method probed after a node error, got %v", sent)
+	}
+	if caller.Aggregator() != Aggregate3 {
+		t.Fatalf("expected %s kept, got %s", Aggregate3, caller.Aggregator())
+	}
+}
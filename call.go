@@ -5,16 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"io"
+	"math/big"
+	"os"
 	"reflect"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 )
 
 type ContractOptions struct {
-	abi     *abi.ABI
-	address common.Address
-	err     error
+	abi            *abi.ABI
+	address        common.Address
+	errors         []abi.Error
+	matchByName    bool
+	bigIntAsNumber bool
+	strictDecode   bool
+	err            error
 }
 
 type ContractOption func(*ContractOptions)
@@ -37,16 +47,92 @@ func WithABIJSON(abiJSON string) ContractOption {
 	}
 }
 
+// WithABIFile reads the JSON ABI from the file at path.
+func WithABIFile(path string) ContractOption {
+	return func(o *ContractOptions) {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			o.err = fmt.Errorf("failed to read abi file: %v", err)
+			return
+		}
+		o.abi, o.err = ParseABI(string(b))
+	}
+}
+
+// WithABIReader reads the JSON ABI from r, e.g. a file of an embedded FS.
+func WithABIReader(r io.Reader) ContractOption {
+	return func(o *ContractOptions) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			o.err = fmt.Errorf("failed to read abi: %v", err)
+			return
+		}
+		o.abi, o.err = ParseABI(string(b))
+	}
+}
+
+// WithErrors registers custom errors that the contract may revert with,
+// in addition to the errors declared by its ABI.
+func WithErrors(errs ...abi.Error) ContractOption {
+	return func(o *ContractOptions) {
+		o.errors = append(o.errors, errs...)
+	}
+}
+
+// WithErrorsJSON registers the custom errors declared by the given ABI JSON.
+func WithErrorsJSON(abiJSON string) ContractOption {
+	return func(o *ContractOptions) {
+		parsed, err := ParseABI(abiJSON)
+		if err != nil {
+			o.err = err
+			return
+		}
+		for _, errABI := range parsed.Errors {
+			o.errors = append(o.errors, errABI)
+		}
+	}
+}
+
+// WithOutputNameMatching makes the contract decode outputs into the struct field
+// whose name matches the ABI output name case-insensitively (e.g. field Balance
+// for output balance) instead of by position. Fields without a matching output
+// name are still decoded by position.
+func WithOutputNameMatching() ContractOption {
+	return func(o *ContractOptions) {
+		o.matchByName = true
+	}
+}
+
+// WithStrictDecode makes decoding fail when the number of outputs of a method
+// differs from the number of fields of the outputs struct, instead of ignoring
+// the extra outputs.
+func WithStrictDecode() ContractOption {
+	return func(o *ContractOptions) {
+		o.strictDecode = true
+	}
+}
+
 func WithAddress(address common.Address) ContractOption {
 	return func(o *ContractOptions) {
 		o.address = address
 	}
 }
 
+// ErrUnknownRevert is returned when revert data matches no known error.
+var ErrUnknownRevert = errors.New("revert data does not match any known error")
+
 // Contract wraps the parsed ABI and acts as a call factory.
 type Contract struct {
-	abi     *abi.ABI
-	address common.Address
+	abi            *abi.ABI
+	address        common.Address
+	errors         map[[4]byte]abi.Error
+	matchByName    bool
+	bigIntAsNumber bool
+	strictDecode   bool
+	// plans caches the decode plans of the methods per outputs type.
+	plans sync.Map
+	// tags caches the parsed field tags per outputs type.
+	tags sync.Map
 }
 
 func NewContract(fns ...ContractOption) (*Contract, error) {
@@ -60,9 +146,20 @@ func NewContract(fns ...ContractOption) (*Contract, error) {
 	if opts.abi == nil {
 		return nil, errors.New("abi is required")
 	}
+	errs := make(map[[4]byte]abi.Error)
+	for _, errABI := range opts.abi.Errors {
+		errs[[4]byte(errABI.ID[:4])] = errABI
+	}
+	for _, errABI := range opts.errors {
+		errs[[4]byte(errABI.ID[:4])] = errABI
+	}
 	return &Contract{
-		abi:     opts.abi,
-		address: opts.address,
+		abi:            opts.abi,
+		address:        opts.address,
+		errors:         errs,
+		matchByName:    opts.matchByName,
+		bigIntAsNumber: opts.bigIntAsNumber,
+		strictDecode:   opts.strictDecode,
 	}, nil
 }
 
@@ -88,6 +185,43 @@ type Call struct {
 	Outputs  any
 	CanFail  bool
 	Failed   bool
+	// RevertReason is the decoded revert reason when the call failed, or the
+	// hex revert data when it cannot be decoded.
+	RevertReason string
+	// CustomError is the decoded custom error when the call failed with one
+	// known by the contract.
+	CustomError *CustomError
+	// RevertInto is the optional struct to decode a custom error into
+	// when the call fails.
+	RevertInto any
+	// Gas is the optional gas needed by the call when simulated. Multicall3
+	// forwards all the available gas to its calls, so it does not limit the call
+	// itself but adds up to the gas limit of simulations, see TotalGas.
+	Gas uint64
+	// Value is the optional ETH value sent with the call, see Caller.CallValue.
+	Value *big.Int
+	// Expect is the optional expected outputs, see Expecting.
+	Expect any
+	// Unexpected reports whether the decoded outputs differ from Expect.
+	Unexpected bool
+	// Meta is arbitrary user data carried along the call, never inspected.
+	Meta map[string]any
+	// ReturnData is the raw data returned by the call, or its revert data if it failed.
+	ReturnData []byte
+	// UpdatedAt is the time the call result was last received.
+	UpdatedAt time.Time
+	// Err is the error of the last invocation: ErrCallFailed when the call failed,
+	// or the error decoding its outputs.
+	Err error
+
+	// outputs overrides the outputs of the method when decoding, see WithOutputABI.
+	outputs abi.Arguments
+	// err is set when building the call failed, and returned by Pack.
+	err error
+	// packed caches the calldata returned by Pack.
+	packed *packCache
+	// rawInput is the calldata sent instead of the packed inputs, see WithRawInput.
+	rawInput []byte
 }
 
 // NewCall creates a new call using given inputs.
@@ -101,6 +235,104 @@ func (contract *Contract) NewCall(outputs any, methodName string, inputs ...any)
 	}
 }
 
+// CheckCall validates the call against the contract ABI without any RPC:
+// the method must exist, the inputs must pack and, when outputs are set,
+// the output struct must fit the method outputs.
+func (contract *Contract) CheckCall(call *Call) error {
+	if call.err != nil {
+		return call.err
+	}
+	method, ok := contract.abi.Methods[call.Method]
+	if !ok {
+		return fmt.Errorf("method '%s' not found in abi", call.Method)
+	}
+	if call.rawInput == nil {
+		if err := validateInputs(method, call.Inputs); err != nil {
+			return err
+		}
+	}
+	if call.Outputs == nil {
+		return nil
+	}
+	switch call.Outputs.(type) {
+	case OutputUnmarshaler, *map[string]any:
+		return nil
+	}
+	t := reflect.ValueOf(call.Outputs)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !t.IsValid() {
+		return fmt.Errorf("outputs of '%s' is a nil %T", call.Method, call.Outputs)
+	}
+	args := method.Outputs
+	if call.outputs != nil {
+		args = call.outputs
+	}
+	if !isOutputStruct(t.Type()) {
+		if len(args) != 1 {
+			return fmt.Errorf("method '%s' returns %d values, outputs of type %T need a single one", call.Method, len(args), call.Outputs)
+		}
+		return nil
+	}
+	outputs := len(args)
+	plan := newDecodePlan(args, t.Type(), contract.matchByName)
+	if plan.flatten {
+		outputs = len(args[0].Type.TupleElems)
+	}
+	if plan.decoded > outputs || contract.strictDecode && plan.decoded != outputs {
+		return fmt.Errorf("method '%s' returns %d values but output struct has %d fields", call.Method, outputs, plan.decoded)
+	}
+	return nil
+}
+
+// Validate checks the call before packing it: the method must exist in the ABI
+// of the contract, and the inputs must match the method inputs in count and type.
+// Every mismatching input is reported.
+func (call *Call) Validate() error {
+	if call.err != nil {
+		return call.err
+	}
+	method, ok := call.Contract.abi.Methods[call.Method]
+	if !ok {
+		return fmt.Errorf("method '%s' not found in abi", call.Method)
+	}
+	if call.rawInput != nil {
+		return nil
+	}
+	return validateInputs(method, call.Inputs)
+}
+
+// validateInputs checks the inputs against the method inputs one by one,
+// for clearer errors than packing them all at once.
+func validateInputs(method abi.Method, inputs []any) error {
+	if len(inputs) != len(method.Inputs) {
+		return fmt.Errorf("method '%s' takes %d inputs, got %d", method.Name, len(method.Inputs), len(inputs))
+	}
+	var errs []error
+	for i, input := range method.Inputs {
+		if _, err := (abi.Arguments{input}).Pack(inputs[i]); err != nil {
+			errs = append(errs, fmt.Errorf("input [%d] '%s' of type %s: cannot use %T: %v", i, input.Name, input.Type, inputs[i], err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid inputs of '%s': %w", method.Name, errors.Join(errs...))
+	}
+	return nil
+}
+
+// BindGetter returns a builder of calls to the given contract method, each
+// decoding into a new T. The method is looked up once, so a misspelled
+// method name is reported when wiring instead of when calling.
+func BindGetter[T any](contract *Contract, method string) (func(inputs ...any) *Call, error) {
+	if _, ok := contract.abi.Methods[method]; !ok {
+		return nil, fmt.Errorf("method '%s' not found in abi", method)
+	}
+	return func(inputs ...any) *Call {
+		return contract.NewCall(new(T), method, inputs...)
+	}, nil
+}
+
 // Name sets a name for the call.
 func (call *Call) Name(name string) *Call {
 	call.CallName = name
@@ -114,8 +346,240 @@ func (call *Call) AllowFailure() *Call {
 	return call
 }
 
+// DecodeRevert sets the struct to decode the custom error into when the call fails.
+// The error must be known by the contract, see WithErrors.
+func (call *Call) DecodeRevert(into any) *Call {
+	call.RevertInto = into
+	return call
+}
+
+// label returns the name of the call, or its method when unnamed.
+func (call *Call) label() string {
+	if call.CallName != "" {
+		return call.CallName
+	}
+	return call.Method
+}
+
+// check validates that the method exists, the inputs pack and
+// the output struct fits the method outputs.
+func (call *Call) check() error {
+	return call.Contract.CheckCall(call)
+}
+
+// WithMeta sets a user metadata value on the call.
+func (call *Call) WithMeta(key string, value any) *Call {
+	if call.Meta == nil {
+		call.Meta = make(map[string]any)
+	}
+	call.Meta[key] = value
+	return call
+}
+
+// Clone returns a copy of the call with its own Meta map.
+// Inputs, Outputs and RevertInto are shared with the original call.
+func (call *Call) Clone() *Call {
+	clone := *call
+	if call.Meta != nil {
+		clone.Meta = make(map[string]any, len(call.Meta))
+		for k, v := range call.Meta {
+			clone.Meta[k] = v
+		}
+	}
+	return &clone
+}
+
+// WithGas sets the gas needed by the call when simulated.
+func (call *Call) WithGas(gas uint64) *Call {
+	call.Gas = gas
+	return call
+}
+
+// WithOutputABI overrides the output arguments of the method used to decode
+// the outputs of this call only, e.g. for a contract returning extra data
+// the contract ABI does not declare.
+func (call *Call) WithOutputABI(outputs []abi.Argument) *Call {
+	if len(outputs) == 0 {
+		call.err = fmt.Errorf("output abi override of '%s' is empty", call.Method)
+		return call
+	}
+	call.outputs = outputs
+	return call
+}
+
+// outputArgs returns the output arguments used to decode the outputs of the method.
+func (call *Call) outputArgs(method string) abi.Arguments {
+	if method == call.Method && call.outputs != nil {
+		return call.outputs
+	}
+	return call.Contract.abi.Methods[method].Outputs
+}
+
+// unpackOutputs unpacks the data as the outputs of the method.
+func (call *Call) unpackOutputs(method string, b []byte) ([]any, error) {
+	if method == call.Method && call.outputs != nil {
+		return call.outputs.Unpack(b)
+	}
+	return call.Contract.abi.Unpack(method, b)
+}
+
+// decodePlan returns the decode plan of the outputs of the method into the struct type.
+func (call *Call) decodePlan(method string, typ reflect.Type) *decodePlan {
+	if method == call.Method && call.outputs != nil {
+		return newDecodePlan(call.outputs, typ, call.Contract.matchByName)
+	}
+	return call.Contract.methodDecodePlan(method, typ)
+}
+
+// Reset clears the result of the last invocation: the failure state, the revert
+// reason, the raw data and the decoded outputs and revert, so the call can be
+// reused. Callers reset the calls they make automatically.
+func (call *Call) Reset() *Call {
+	call.Failed = false
+	call.RevertReason = ""
+	call.CustomError = nil
+	call.Unexpected = false
+	call.ReturnData = nil
+	call.UpdatedAt = time.Time{}
+	call.Err = nil
+	resetValue(call.Outputs)
+	resetValue(call.RevertInto)
+	return call
+}
+
+// resetValue sets the value pointed to by v to its zero value.
+func resetValue(v any) {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv.Elem().SetZero()
+	}
+}
+
+func resetCalls(calls []*Call) {
+	for _, call := range calls {
+		call.Reset()
+	}
+}
+
 // Unpack unpacks and converts EVM outputs and sets struct fields.
+// Empty data of a method without outputs is skipped.
 func (call *Call) Unpack(b []byte) error {
+	if len(b) == 0 {
+		if len(call.outputArgs(call.Method)) == 0 {
+			return nil
+		}
+		return fmt.Errorf("method '%s' returned no data, the target may not be a contract", call.Method)
+	}
+	if err := call.unpackMethod(call.Method, b); err != nil {
+		return err
+	}
+	call.Unexpected = call.Expect != nil && !call.matchesExpectation(b)
+	return nil
+}
+
+// UnpackNested unpacks the outputs of a method returning a (bool success, bytes data)
+// wrapper, then unpacks data as the outputs of innerMethod and sets struct fields.
+func (call *Call) UnpackNested(b []byte, innerMethod string) error {
+	out, err := call.unpackOutputs(call.Method, b)
+	if err != nil {
+		return fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
+	}
+	if len(out) != 2 {
+		return fmt.Errorf("method '%s' returns %d values, not (bool, bytes)", call.Method, len(out))
+	}
+	success, ok := out[0].(bool)
+	if !ok {
+		return fmt.Errorf("method '%s' returns %T as first value, not bool", call.Method, out[0])
+	}
+	data, ok := out[1].([]byte)
+	if !ok {
+		return fmt.Errorf("method '%s' returns %T as second value, not bytes", call.Method, out[1])
+	}
+	if !success {
+		return fmt.Errorf("inner call of '%s' failed: %s", call.Method, revertReason(call.Contract, data))
+	}
+	return call.unpackMethod(innerMethod, data)
+}
+
+// unpackMethod unpacks the data as the outputs of the method and sets struct fields.
+func (call *Call) unpackMethod(method string, b []byte) error {
+	if m, ok := call.Outputs.(*map[string]any); ok {
+		values, err := call.unpackToMap(method, b)
+		if err != nil {
+			return err
+		}
+		*m = values
+		return nil
+	}
+	if u, ok := call.Outputs.(OutputUnmarshaler); ok {
+		out, err := call.unpackOutputs(method, b)
+		if err != nil {
+			return fmt.Errorf("failed to unpack '%s' outputs: %v", method, err)
+		}
+		if err := u.UnmarshalABI(out); err != nil {
+			return fmt.Errorf("failed to unmarshal '%s' outputs: %v", method, err)
+		}
+		return nil
+	}
+
+	t := reflect.ValueOf(call.Outputs)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if !t.IsValid() {
+		return errors.New("outputs type is not a struct")
+	}
+	if !isOutputStruct(t.Type()) {
+		return call.unpackSingle(method, t, b)
+	}
+
+	out, err := call.unpackOutputs(method, b)
+	if err != nil {
+		return fmt.Errorf("failed to unpack '%s' outputs: %v", method, err)
+	}
+
+	plan := call.decodePlan(method, t.Type())
+	out, err = plan.values(out)
+	if err != nil {
+		return fmt.Errorf("failed to unpack '%s' outputs: %v", method, err)
+	}
+	if plan.decoded > len(out) || call.Contract.strictDecode && plan.decoded != len(out) {
+		return fmt.Errorf("method '%s' returns %d values but output struct has %d fields", method, len(out), plan.decoded)
+	}
+	if err := plan.set(t, out); err != nil {
+		return fmt.Errorf("failed to set '%s' outputs: %v", method, err)
+	}
+	return nil
+}
+
+// unpackSingle sets the single output of the method to the scalar or slice
+// pointed to by Outputs.
+func (call *Call) unpackSingle(method string, t reflect.Value, b []byte) error {
+	if !t.CanSet() {
+		return fmt.Errorf("outputs type %T is neither a struct nor a pointer", call.Outputs)
+	}
+	out, err := call.unpackOutputs(method, b)
+	if err != nil {
+		return fmt.Errorf("failed to unpack '%s' outputs: %v", method, err)
+	}
+	if len(out) != 1 {
+		return fmt.Errorf("method '%s' returns %d values, outputs of type %T need a single one", method, len(out), call.Outputs)
+	}
+	if err := setField(t, out[0], fieldTag{}); err != nil {
+		return fmt.Errorf("failed to set '%s' outputs: %v", method, err)
+	}
+	return nil
+}
+
+// isOutputStruct reports whether outputs of the type are decoded field by field.
+// Structs decoded as a single value, like big.Int, are not.
+func isOutputStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != bigIntType
+}
+
+// UnpackOrdered is like Unpack, but sets the output at index fieldOrder[i]
+// to the i-th field of the outputs struct, for structs whose field order
+// differs from the ABI outputs order.
+func (call *Call) UnpackOrdered(b []byte, fieldOrder []int) error {
 	t := reflect.ValueOf(call.Outputs)
 	if t.Kind() == reflect.Pointer {
 		t = t.Elem()
@@ -123,27 +587,190 @@ func (call *Call) Unpack(b []byte) error {
 	if t.Kind() != reflect.Struct {
 		return errors.New("outputs type is not a struct")
 	}
+	if len(fieldOrder) != t.NumField() {
+		return fmt.Errorf("field order has %d indexes but output struct has %d fields", len(fieldOrder), t.NumField())
+	}
 
-	out, err := call.Contract.abi.Unpack(call.Method, b)
+	out, err := call.unpackOutputs(call.Method, b)
 	if err != nil {
 		return fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
 	}
+	for i, index := range fieldOrder {
+		if index < 0 || index >= len(out) {
+			return fmt.Errorf("field order index %d at [%d] out of range, '%s' returns %d values", index, i, call.Method, len(out))
+		}
+	}
 
-	fieldCount := t.NumField()
-	for i := 0; i < fieldCount; i++ {
-		field := t.Field(i)
-		converted := abi.ConvertType(out[i], reflect.New(field.Type()).Interface())
-		field.Set(reflect.ValueOf(converted).Elem())
+	plan := &decodePlan{tags: parseFieldTags(t.Type()), indexes: fieldOrder}
+	if err := plan.set(t, out); err != nil {
+		return fmt.Errorf("failed to set '%s' outputs: %v", call.Method, err)
 	}
 
 	return nil
 }
 
-// Pack converts and packs EVM inputs.
+// UnpackToMap unpacks the outputs of the last invocation (ReturnData) into a map
+// keyed by the output names. Unnamed outputs are keyed by their position,
+// e.g. "0", "1". Unpack fills Outputs the same way when it is a *map[string]any.
+func (call *Call) UnpackToMap() (map[string]any, error) {
+	return call.unpackToMap(call.Method, call.ReturnData)
+}
+
+func (call *Call) unpackToMap(method string, b []byte) (map[string]any, error) {
+	out, err := call.unpackOutputs(method, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack '%s' outputs: %v", method, err)
+	}
+	m := make(map[string]any, len(out))
+	for i, output := range call.outputArgs(method) {
+		name := output.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		m[name] = out[i]
+	}
+	return m, nil
+}
+
+// UnpackMaps unpacks the outputs of a method returning a single array of tuples
+// into one map per tuple, keyed by the tuple component names.
+func (call *Call) UnpackMaps(b []byte) ([]map[string]any, error) {
+	outputs := call.outputArgs(call.Method)
+	if len(outputs) != 1 || (outputs[0].Type.T != abi.SliceTy && outputs[0].Type.T != abi.ArrayTy) ||
+		outputs[0].Type.Elem.T != abi.TupleTy {
+		return nil, fmt.Errorf("method '%s' does not return a single array of tuples", call.Method)
+	}
+	names := outputs[0].Type.Elem.TupleRawNames
+
+	out, err := call.unpackOutputs(call.Method, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
+	}
+
+	arr := reflect.ValueOf(out[0])
+	maps := make([]map[string]any, arr.Len())
+	for i := range maps {
+		tuple := reflect.Indirect(arr.Index(i))
+		maps[i] = make(map[string]any, len(names))
+		for j, name := range names {
+			maps[i][name] = tuple.Field(j).Interface()
+		}
+	}
+	return maps, nil
+}
+
+// TypedValue is an unpacked output value with its Solidity type.
+type TypedValue struct {
+	SolidityType string
+	Value        any
+}
+
+// UnpackTyped unpacks the outputs along with their Solidity types,
+// e.g. for displaying values of any method.
+func (call *Call) UnpackTyped(b []byte) ([]TypedValue, error) {
+	out, err := call.unpackOutputs(call.Method, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
+	}
+
+	outputs := call.outputArgs(call.Method)
+	values := make([]TypedValue, len(out))
+	for i, value := range out {
+		values[i] = TypedValue{
+			SolidityType: outputs[i].Type.String(),
+			Value:        value,
+		}
+	}
+	return values, nil
+}
+
+// UnpackRevert matches the revert data of a failed call against the errors
+// known by the contract and sets the decoded error fields to RevertInto.
+// ErrUnknownRevert is returned when no known error matches.
+func (call *Call) UnpackRevert(b []byte) error {
+	t := reflect.ValueOf(call.RevertInto)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return errors.New("revert type is not a struct")
+	}
+
+	if len(b) < 4 {
+		return ErrUnknownRevert
+	}
+	errABI, ok := call.Contract.errors[[4]byte(b[:4])]
+	if !ok {
+		return ErrUnknownRevert
+	}
+	out, err := errABI.Inputs.Unpack(b[4:])
+	if err != nil {
+		return fmt.Errorf("failed to unpack '%s' error: %v", errABI.Name, err)
+	}
+
+	if err := positionalDecodePlan(t.Type()).set(t, out); err != nil {
+		return fmt.Errorf("failed to set '%s' error: %v", errABI.Name, err)
+	}
+
+	return nil
+}
+
+// Pack converts and packs EVM inputs. The calldata is cached, see InvalidateCache.
 func (call *Call) Pack() ([]byte, error) {
+	if call.err != nil {
+		return nil, call.err
+	}
+	if call.rawInput != nil {
+		return call.rawInput, nil
+	}
+	if call.packed.matches(call) {
+		return call.packed.data, nil
+	}
 	b, err := call.Contract.abi.Pack(call.Method, call.Inputs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to pack '%s' inputs: %v", call.Method, err)
 	}
+	call.packed = newPackCache(call, b)
 	return b, nil
 }
+
+// WithRawInput sets the calldata sent for the call as is, instead of packing
+// the inputs, for contracts expecting non-standard encodings. The data must
+// include the method selector. Outputs are still decoded with the method ABI.
+func (call *Call) WithRawInput(data []byte) *Call {
+	call.rawInput = data
+	return call
+}
+
+// InvalidateCache drops the calldata cached by Pack, which is only packed again
+// when the contract, the method or the Inputs slice are reassigned. It must be
+// called after mutating the elements of Inputs in place.
+func (call *Call) InvalidateCache() *Call {
+	call.packed = nil
+	return call
+}
+
+// packCache is the calldata packed for a contract, method and inputs slice.
+type packCache struct {
+	contract *Contract
+	method   string
+	inputs   uintptr
+	n        int
+	data     []byte
+}
+
+func newPackCache(call *Call, data []byte) *packCache {
+	return &packCache{
+		contract: call.Contract,
+		method:   call.Method,
+		inputs:   reflect.ValueOf(call.Inputs).Pointer(),
+		n:        len(call.Inputs),
+		data:     data,
+	}
+}
+
+// matches reports whether the cache was packed for the current call.
+func (c *packCache) matches(call *Call) bool {
+	return c != nil && c.contract == call.Contract && c.method == call.Method &&
+		c.inputs == reflect.ValueOf(call.Inputs).Pointer() && c.n == len(call.Inputs)
+}
@@ -0,0 +1,121 @@
+package multicall
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCallNilOutputs(t *testing.T) {
+	caller := newTestCaller(t, newFakeChain(t))
+	c := mustContract(t, testABI, tokenAddress)
+
+	type reserves struct {
+		Reserve0 *big.Int
+	}
+	var outputs *reserves
+	call := c.NewCall(outputs, "getReserves")
+	if _, err := caller.Call(nil, call); err == nil || call.Err == nil || call.Err.Error() != "outputs type is not a struct" {
+		t.Fatalf("expected error for nil outputs, got %v", err)
+	}
+	if err := c.CheckCall(c.NewCall(outputs, "getReserves")); err == nil {
+		t.Fatal("expected CheckCall error for nil outputs")
+	}
+}
+
+func TestCheckCall(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	strict := mustContract(t, testABI, tokenAddress, WithStrictDecode())
+
+	type tooMany struct {
+		A, B, C, D *big.Int
+	}
+	type fewer struct {
+		Reserve0 *big.Int
+	}
+	tests := []struct {
+		name  string
+		call  *Call
+		valid bool
+	}{
+		{"valid", c.NewCall(new(big.Int), "balanceOf", ownerAddress), true},
+		{"no outputs", c.NewCall(nil, "balanceOf", ownerAddress), true},
+		{"unknown method", c.NewCall(nil, "totalSupply"), false},
+		{"input count", c.NewCall(nil, "balanceOf"), false},
+		{"input type", c.NewCall(nil, "balanceOf", big.NewInt(1)), false},
+		{"too many fields", c.NewCall(new(tooMany), "getReserves"), false},
+		{"fewer fields", c.NewCall(new(fewer), "getReserves"), true},
+		{"fewer fields strict", strict.NewCall(new(fewer), "getReserves"), false},
+		{"scalar for many outputs", c.NewCall(new(big.Int), "getReserves"), false},
+		{"map outputs", c.NewCall(new(map[string]any), "getReserves"), true},
+		{"raw input", c.NewCall(nil, "balanceOf").WithRawInput([]byte{0x70, 0xa0, 0x82, 0x31}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call.Contract.CheckCall(tt.call)
+			if tt.valid != (err == nil) {
+				t.Fatalf("expected valid %v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
+
+func TestCallEmptyReturnData(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	chain.handle = func(block *big.Int, call subCall) (bool, []byte) {
+		if method, _ := c.abi.MethodById(call.Data); method != nil && method.Name == "name" {
+			return true, nil
+		}
+		return handleTestCall(t, c, call)
+	}
+	caller := newTestCaller(t, chain)
+
+	ping := c.NewCall(nil, "ping")
+	balance := c.NewCall(new(big.Int), "balanceOf", ownerAddress)
+	if _, err := caller.Call(nil, ping, balance); err != nil {
+		t.Fatal(err)
+	}
+	if ping.Failed || ping.Err != nil || len(ping.ReturnData) != 0 {
+		t.Fatalf("unexpected void call result: failed %v, err %v", ping.Failed, ping.Err)
+	}
+	if balance.Outputs.(*big.Int).Int64() != 0xbb {
+		t.Fatalf("unexpected balance %s", balance.Outputs)
+	}
+
+	name := c.NewCall(new(string), "name")
+	if _, err := caller.Call(nil, name); err == nil || name.Err == nil || name.Failed {
+		t.Fatalf("expected decode error for empty data of a method with outputs, got %v", err)
+	}
+}
+
+func TestCallWithRawInput(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+
+	// balanceOf(0x...cc) encoded by hand
+	raw := append(common.FromHex("0x70a08231"), common.LeftPadBytes([]byte{0xcc}, 32)...)
+	calls := []*Call{
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress),
+		c.NewCall(new(big.Int), "balanceOf").WithRawInput(raw),
+		c.NewCall(new(string), "name"),
+	}
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	if got := calls[0].Outputs.(*big.Int).Int64(); got != 0xbb {
+		t.Fatalf("unexpected balance %d", got)
+	}
+	if got := calls[1].Outputs.(*big.Int).Int64(); got != 0xcc {
+		t.Fatalf("unexpected raw input balance %d", got)
+	}
+	if got := *calls[2].Outputs.(*string); got != "Token" {
+		t.Fatalf("unexpected name %q", got)
+	}
+	if sent := chain.sent[0][1].Data; !bytes.Equal(sent, raw) {
+		t.Fatalf("expected raw calldata %x, got %x", raw, sent)
+	}
+}
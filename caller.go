@@ -2,13 +2,18 @@ package multicall
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/pinealctx/multicall/contract"
+	"math/big"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"golang.org/x/time/rate"
 )
 
 // DefaultAddress is the same for all chains (Multicall3).
@@ -16,10 +21,30 @@ import (
 const DefaultAddress = "0xcA11bde05977b3631167028862bE2a173976CA11"
 
 type Options struct {
-	ctx             context.Context
-	rpcURL          string
-	client          bind.ContractCaller
-	contractAddress string
+	ctx                  context.Context
+	rpcURL               string
+	rpcURLs              []string
+	client               bind.ContractCaller
+	contractAddress      string
+	logger               Logger
+	tracePrefix          string
+	blockNumber          *big.Int
+	rawCapture           func(reqBody, respBody []byte)
+	strict               bool
+	nodeErrorRetries     int
+	retryAttempts        int
+	retryBackoff         time.Duration
+	retryable            func(error) bool
+	defaultCooldown      time.Duration
+	autoSplit            bool
+	observer             Observer
+	deduplicate          bool
+	maxFeePerGas         *big.Int
+	maxPriorityFeePerGas *big.Int
+	maxRequestBytes      int
+	progress             func(chunkIndex, totalChunks, callsDone int)
+	onResult             func(*Call)
+	limiter              *rate.Limiter
 }
 
 type Option func(*Options)
@@ -30,6 +55,24 @@ func WithRPCURL(url string) Option {
 	}
 }
 
+// WithRPCURLs makes the caller dial every RPC URL and spread its eth_calls
+// over them in turn. An eth_call failing with a node or transport error is
+// sent to the next endpoint, and an endpoint failing repeatedly is taken out
+// of rotation for a while. It takes precedence over WithRPCURL.
+func WithRPCURLs(urls ...string) Option {
+	return func(o *Options) {
+		o.rpcURLs = urls
+	}
+}
+
+// WithContext sets the context used to dial the RPC URL. It is also used for
+// every multicall whose CallOpts has no context set.
+func WithContext(ctx context.Context) Option {
+	return func(o *Options) {
+		o.ctx = ctx
+	}
+}
+
 func WithClient(client bind.ContractCaller) Option {
 	return func(o *Options) {
 		o.client = client
@@ -42,9 +85,214 @@ func WithContractAddress(address string) Option {
 	}
 }
 
+// WithLogger sets the logger used to report multicall activity.
+func WithLogger(logger Logger) Option {
+	return func(o *Options) {
+		o.logger = logger
+	}
+}
+
+// WithTracePrefix sets a prefix prepended to every log line of the caller,
+// so logs of concurrent callers can be told apart.
+func WithTracePrefix(prefix string) Option {
+	return func(o *Options) {
+		o.tracePrefix = prefix
+	}
+}
+
+// WithLatestBlock makes the caller read at the latest block by default.
+// This is the default behavior.
+func WithLatestBlock() Option {
+	return func(o *Options) {
+		o.blockNumber = nil
+	}
+}
+
+// WithPinnedBlock makes the caller read at the given block by default.
+// A non-nil CallOpts.BlockNumber passed to a call still takes precedence.
+func WithPinnedBlock(blockNumber *big.Int) Option {
+	return func(o *Options) {
+		o.blockNumber = blockNumber
+	}
+}
+
+// WithRawCapture sets a function receiving the raw JSON-RPC request and response
+// bodies of every request, for debugging. Capturing is only possible when the
+// caller dials an HTTP RPC URL itself, it is silently skipped for other transports
+// and for clients set with WithClient.
+func WithRawCapture(capture func(reqBody, respBody []byte)) Option {
+	return func(o *Options) {
+		o.rawCapture = capture
+	}
+}
+
+// WithStrictValidation makes the caller validate every call before sending:
+// the method must exist in the ABI, the inputs must pack and the output struct
+// must not have more fields than the method outputs. All offending calls are
+// reported in a single error.
+func WithStrictValidation() Option {
+	return func(o *Options) {
+		o.strict = true
+	}
+}
+
+// WithNodeErrorRetry makes the caller retry a multicall up to the given number
+// of times after the first try when it fails because of the node (e.g. an
+// internal error or a dropped connection), without waiting. Contract reverts
+// are deterministic and never retried. WithRetry takes precedence.
+func WithNodeErrorRetry(retries int) Option {
+	return func(o *Options) {
+		o.nodeErrorRetries = retries
+	}
+}
+
+// WithRetry makes the caller try a multicall up to the given number of attempts
+// in total, the first try included, when it fails with a retryable error, see
+// WithRetryPredicate. It waits between attempts with an exponential backoff
+// starting at the given duration, doubled at every attempt, with a jitter of
+// up to half of it. It takes precedence over WithNodeErrorRetry.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(o *Options) {
+		o.retryAttempts = attempts
+		o.retryBackoff = backoff
+	}
+}
+
+// WithRetryPredicate sets the function classifying the errors of a multicall
+// as retryable. By default node and transport errors are retried, while
+// contract reverts are not. Context errors and errors of the rate limit set
+// with WithRateLimit are never retried.
+func WithRetryPredicate(retryable func(error) bool) Option {
+	return func(o *Options) {
+		o.retryable = retryable
+	}
+}
+
+// WithDefaultCooldown sets the cooldown CallChunked sleeps between chunks
+// when it is called with a zero cooldown. A non-zero cooldown argument
+// still takes precedence.
+func WithDefaultCooldown(cooldown time.Duration) Option {
+	return func(o *Options) {
+		o.defaultCooldown = cooldown
+	}
+}
+
+// WithAutoSplit makes the caller split the calls in halves and send each half
+// separately when a multicall runs out of gas or exceeds the number of calls
+// allowed by the provider. A single call running out of gas alone fails with
+// ErrCallTooLarge. The detected provider limit is kept, see Caller.CallLimit.
+func WithAutoSplit() Option {
+	return func(o *Options) {
+		o.autoSplit = true
+	}
+}
+
+// WithObserver sets the observer receiving hooks about the multicalls.
+func WithObserver(observer Observer) Option {
+	return func(o *Options) {
+		o.observer = observer
+	}
+}
+
+// WithDeduplicate makes the caller send identical calls (same target,
+// calldata and failure mode) only once per multicall, setting the result
+// to all of them. The savings are reported to an Observer implementing
+// DedupObserver, or returned by CallDedup.
+func WithDeduplicate() Option {
+	return func(o *Options) {
+		o.deduplicate = true
+	}
+}
+
+// WithFeeContext makes the caller send every eth_call with the given EIP-1559
+// fee fields, for simulating calls depending on the base fee or gas price.
+// CallOpts has no fee fields, so the client is wrapped to set them. A nil fee
+// leaves the field as set by the call, e.g. from the TransactOpts of CallValue.
+// Calls setting a gas price fail, as nodes reject it along with these fees.
+func WithFeeContext(maxFeePerGas, maxPriorityFeePerGas *big.Int) Option {
+	return func(o *Options) {
+		o.maxFeePerGas = maxFeePerGas
+		o.maxPriorityFeePerGas = maxPriorityFeePerGas
+	}
+}
+
+// WithMaxRequestBytes sets the maximum size of the calldata of a multicall.
+// Larger multicalls fail with ErrRequestTooLarge before being sent, instead of
+// being rejected by the provider.
+func WithMaxRequestBytes(n int) Option {
+	return func(o *Options) {
+		o.maxRequestBytes = n
+	}
+}
+
+// WithProgress sets a function called by CallChunked after each chunk completes,
+// with the index of the chunk, the number of chunks and the number of calls done
+// so far. It is called from the chunk loop goroutine, never concurrently.
+func WithProgress(progress func(chunkIndex, totalChunks, callsDone int)) Option {
+	return func(o *Options) {
+		o.progress = progress
+	}
+}
+
+// WithOnResult sets a function called with every call successfully decoded,
+// in index order, as the results of a multicall are decoded. It runs
+// synchronously within the multicall, before it returns.
+func WithOnResult(onResult func(*Call)) Option {
+	return func(o *Options) {
+		o.onResult = onResult
+	}
+}
+
+// WithRateLimit limits the multicalls sent by the caller, and every caller
+// derived from it, to requestsPerSecond with bursts of up to burst multicalls,
+// waiting as needed before each of them, including retries and the chunks of
+// concurrent calls. A burst below 1 is raised to 1, as no multicall could be
+// sent otherwise.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(o *Options) {
+		o.limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), max(burst, 1))
+	}
+}
+
+// Logger is the minimal logging interface used by the caller.
+// *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// ErrAllowFailureUnsupported is returned when a call allowed to fail is passed
+// to an aggregate method that cannot tolerate failures.
+var ErrAllowFailureUnsupported = errors.New("allow failure is not supported by legacy aggregate, use aggregate3")
+
+// ErrCallFailed is set to Call.Err when a call allowed to fail failed.
+var ErrCallFailed = errors.New("call failed")
+
 // Caller makes multicalls.
 type Caller struct {
-	contract contract.Interface
+	ctx              context.Context
+	contract         contract.Interface
+	address          common.Address
+	client           bind.ContractCaller
+	vias             *sync.Map
+	logger           Logger
+	tracePrefix      string
+	blockNumber      *big.Int
+	strict           bool
+	nodeErrorRetries int
+	retryBackoff     time.Duration
+	retryable        func(error) bool
+	defaultCooldown  time.Duration
+	autoSplit        bool
+	observer         Observer
+	deduplicate      bool
+	callLimit        *atomic.Int64
+	aggregator       *atomic.Int32
+	maxRequestBytes  int
+	progress         func(chunkIndex, totalChunks, callsDone int)
+	onResult         func(*Call)
+	limiter          *rate.Limiter
+	// ownsClient is set when the client was dialed by New, and is closed by Close.
+	ownsClient bool
 }
 
 func New(fns ...Option) (*Caller, error) {
@@ -57,36 +305,398 @@ func New(fns ...Option) (*Caller, error) {
 	}
 
 	var err error
+	ownsClient := opts.client == nil
+	if opts.client == nil && len(opts.rpcURLs) > 0 {
+		opts.client, err = dialEndpoints(opts.ctx, opts.rpcURLs, opts.rawCapture)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if opts.client == nil {
 		if opts.rpcURL == "" {
 			return nil, fmt.Errorf("rpcURL is required")
 		}
-		if opts.ctx == nil {
-			opts.client, err = ethclient.Dial(opts.rpcURL)
-		} else {
-			opts.client, err = ethclient.DialContext(opts.ctx, opts.rpcURL)
-		}
+		opts.client, err = dial(opts.ctx, opts.rpcURL, opts.rawCapture)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	c, err := contract.NewMulticallCaller(common.HexToAddress(opts.contractAddress), opts.client)
+	if opts.maxFeePerGas != nil || opts.maxPriorityFeePerGas != nil {
+		opts.client = &feeCaller{
+			ContractCaller:       opts.client,
+			maxFeePerGas:         opts.maxFeePerGas,
+			maxPriorityFeePerGas: opts.maxPriorityFeePerGas,
+		}
+	}
+
+	address := common.HexToAddress(opts.contractAddress)
+	c, err := contract.NewMulticallCaller(address, opts.client)
 	if err != nil {
 		return nil, err
 	}
-	return &Caller{contract: c}, nil
+	retries := opts.nodeErrorRetries
+	if opts.retryAttempts > 0 {
+		retries = opts.retryAttempts - 1
+	}
+	return &Caller{
+		ctx:              opts.ctx,
+		contract:         c,
+		address:          address,
+		client:           opts.client,
+		vias:             &sync.Map{},
+		logger:           opts.logger,
+		tracePrefix:      opts.tracePrefix,
+		blockNumber:      opts.blockNumber,
+		strict:           opts.strict,
+		nodeErrorRetries: retries,
+		retryBackoff:     opts.retryBackoff,
+		retryable:        opts.retryable,
+		defaultCooldown:  opts.defaultCooldown,
+		autoSplit:        opts.autoSplit,
+		observer:         opts.observer,
+		deduplicate:      opts.deduplicate,
+		callLimit:        &atomic.Int64{},
+		aggregator:       &atomic.Int32{},
+		maxRequestBytes:  opts.maxRequestBytes,
+		progress:         opts.progress,
+		onResult:         opts.onResult,
+		limiter:          opts.limiter,
+		ownsClient:       ownsClient,
+	}, nil
+}
+
+// Close closes the client dialed by New. A client set with WithClient is owned
+// by the user and left open. Callers derived with WithContext share the client
+// of the original caller, so only one of them should be closed.
+func (caller *Caller) Close() {
+	if !caller.ownsClient {
+		return
+	}
+	if closer, ok := caller.Client().(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// Client returns the client dialed by New or set with WithClient. The fee
+// fields set with WithFeeContext are not applied to its eth_calls.
+func (caller *Caller) Client() bind.ContractCaller {
+	if fee, ok := caller.client.(*feeCaller); ok {
+		return fee.ContractCaller
+	}
+	return caller.client
+}
+
+// ContractAddress returns the address of the multicall contract of the caller.
+func (caller *Caller) ContractAddress() common.Address {
+	return caller.address
+}
 
+// dial dials the RPC URL, capturing the raw requests when capture is set.
+func dial(ctx context.Context, url string, capture func(reqBody, respBody []byte)) (*ethclient.Client, error) {
+	if capture != nil {
+		return dialWithCapture(ctx, url, capture)
+	}
+	if ctx == nil {
+		return ethclient.Dial(url)
+	}
+	return ethclient.DialContext(ctx, url)
+}
+
+func (caller *Caller) logf(format string, v ...any) {
+	if caller.logger == nil {
+		return
+	}
+	if caller.tracePrefix != "" {
+		format = caller.tracePrefix + " " + format
+	}
+	caller.logger.Printf(format, v...)
+}
+
+// WithContext returns a copy of the caller that uses ctx for every multicall
+// whose CallOpts has no context set. The original caller is not modified.
+func (caller *Caller) WithContext(ctx context.Context) *Caller {
+	derived := *caller
+	derived.ctx = ctx
+	return &derived
+}
+
+// callOpts returns a copy of the call options with the caller defaults applied.
+func (caller *Caller) callOpts(opts *bind.CallOpts) *bind.CallOpts {
+	resolved := &bind.CallOpts{}
+	if opts != nil {
+		*resolved = *opts
+	}
+	if resolved.Context == nil && caller.ctx != nil {
+		resolved.Context = caller.ctx
+	}
+	if resolved.BlockNumber == nil && caller.blockNumber != nil {
+		resolved.BlockNumber = new(big.Int).Set(caller.blockNumber)
+	}
+	return resolved
 }
 
 // Call makes multicalls.
+// Defaults of the caller (e.g. the pinned block) are applied to unset fields of opts.
 func (caller *Caller) Call(opts *bind.CallOpts, calls ...*Call) ([]*Call, error) {
+	return caller.execute(caller.contract, opts, calls)
+}
+
+// Refresh re-runs the calls matching the predicate, updating them in place,
+// and returns all given calls. It is useful for refreshing stale results,
+// e.g. by checking Call.UpdatedAt against a TTL. The calls are re-run at the
+// block of opts, or the latest block when it is unset, even if the caller has
+// a block pinned with WithPinnedBlock.
+func (caller *Caller) Refresh(opts *bind.CallOpts, calls []*Call, predicate func(*Call) bool) ([]*Call, error) {
+	if predicate == nil {
+		return calls, errors.New("refresh predicate is nil")
+	}
+	var stale []*Call
+	for _, call := range calls {
+		if predicate(call) {
+			stale = append(stale, call)
+		}
+	}
+	if len(stale) == 0 {
+		return calls, nil
+	}
+	latest := *caller
+	latest.blockNumber = nil
+	if _, err := latest.Call(opts, stale...); err != nil {
+		return calls, fmt.Errorf("refresh failed: %v", err)
+	}
+	return calls, nil
+}
+
+// RetryFailedAtBlock re-runs the failed calls at the given block, e.g. one block
+// behind the tip when reads failed because of a reorg, updating them in place,
+// and returns all given calls. Calls failing again keep failing, with the
+// revert reason at that block.
+func (caller *Caller) RetryFailedAtBlock(opts *bind.CallOpts, calls []*Call, block *big.Int) ([]*Call, error) {
+	var failed []*Call
+	for _, call := range calls {
+		if call.Failed {
+			failed = append(failed, call)
+		}
+	}
+	if len(failed) == 0 {
+		return calls, nil
+	}
+	blockOpts := &bind.CallOpts{}
+	if opts != nil {
+		*blockOpts = *opts
+	}
+	blockOpts.BlockNumber = block
+	if _, err := caller.Call(blockOpts, failed...); err != nil {
+		return calls, fmt.Errorf("retry at block %s failed: %v", block, err)
+	}
+	return calls, nil
+}
+
+// CallVia makes multicalls through the multicall contract at the given address
+// instead of the one the caller was created with. Contracts bound to other
+// addresses are cached and reused.
+func (caller *Caller) CallVia(address common.Address, opts *bind.CallOpts, calls ...*Call) ([]*Call, error) {
+	c, err := caller.contractAt(address)
+	if err != nil {
+		return calls, err
+	}
+	return caller.execute(c, opts, calls)
+}
+
+func (caller *Caller) contractAt(address common.Address) (contract.Interface, error) {
+	if c, ok := caller.vias.Load(address); ok {
+		return c.(contract.Interface), nil
+	}
+	c, err := contract.NewMulticallCaller(address, caller.client)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := caller.vias.LoadOrStore(address, c)
+	return actual.(contract.Interface), nil
+}
+
+// execute makes the multicall through the given contract with the method
+// detected by Autodetect, splitting aggregate3 multicalls when auto split
+// is enabled.
+func (caller *Caller) execute(c contract.Interface, opts *bind.CallOpts, calls []*Call) ([]*Call, error) {
+	switch caller.Aggregator() {
+	case TryAggregate:
+		return caller.callTryAggregate(c, opts, false, calls)
+	case Aggregate:
+		_, calls, err := caller.callAggregate(c, opts, calls)
+		return calls, err
+	}
+	if caller.autoSplit {
+		return caller.callSplitting(c, opts, calls)
+	}
+	return caller.call(c, opts, calls)
+}
+
+func (caller *Caller) call(c contract.Interface, opts *bind.CallOpts, calls []*Call) ([]*Call, error) {
+	calls, _, err := caller.aggregate3(c, opts, calls, caller.deduplicate)
+	return calls, err
+}
+
+// aggregate3 makes the multicall with aggregate3, deduplicating the calls
+// first if asked to. The stats are zero without deduplication.
+func (caller *Caller) aggregate3(c contract.Interface, opts *bind.CallOpts, calls []*Call, dedup bool) ([]*Call, DedupStats, error) {
+	var stats DedupStats
+	opts = caller.callOpts(opts)
+	if err := caller.validate(calls); err != nil {
+		return calls, stats, err
+	}
+	resetCalls(calls)
+	multiCalls, err := packCalls(calls)
+	if err != nil {
+		return calls, stats, err
+	}
+	var indexes []int
+	if dedup {
+		multiCalls, indexes, stats = deduplicate(multiCalls)
+		caller.observeDeduplicated(stats)
+	}
+	if err := caller.checkRequestSize(len(multiCalls), "aggregate3", multiCalls); err != nil {
+		return calls, stats, err
+	}
+
+	var calldataBytes int
+	for _, multiCall := range multiCalls {
+		calldataBytes += len(multiCall.CallData)
+	}
+	caller.observeEncoded(calldataBytes)
+
+	caller.logf("multicall: sending %d calls", len(multiCalls))
+	var results []contract.Multicall3Result
+	err = caller.retry(opts.Context, func() (err error) {
+		results, err = c.Aggregate3(opts, multiCalls)
+		return err
+	})
+	if err != nil {
+		caller.logf("multicall: failed: %v", err)
+		return calls, stats, multicallError(err)
+	}
+
+	var returnBytes int
+	for _, result := range results {
+		returnBytes += len(result.ReturnData)
+	}
+	caller.observeDecoded(returnBytes)
+
+	if indexes != nil {
+		results = duplicateResults(results, indexes)
+	}
+	if err := unpackResults(calls, results, caller.onResult); err != nil {
+		return calls, stats, err
+	}
+	return calls, stats, nil
+}
+
+// CallAggregate makes multicalls using the legacy aggregate method, available on
+// all multicall versions, which reverts the whole batch if any call fails.
+// Calls allowed to fail are rejected with ErrAllowFailureUnsupported, use Call
+// (aggregate3) or CallTryAggregate for them instead. It returns the number of
+// the block the calls were executed at, like CallAt.
+func (caller *Caller) CallAggregate(opts *bind.CallOpts, calls ...*Call) (*big.Int, []*Call, error) {
+	return caller.callAggregate(caller.contract, opts, calls)
+}
+
+// CallTryAggregate makes multicalls using the tryAggregate method of Multicall2
+// and Multicall3. With requireSuccess, the whole batch reverts if any call fails.
+// Otherwise failed calls are reported like with Call, and a failed call not
+// allowed to fail fails the multicall after the fact.
+func (caller *Caller) CallTryAggregate(opts *bind.CallOpts, requireSuccess bool, calls ...*Call) ([]*Call, error) {
+	return caller.callTryAggregate(caller.contract, opts, requireSuccess, calls)
+}
+
+func (caller *Caller) callAggregate(c contract.Interface, opts *bind.CallOpts, calls []*Call) (*big.Int, []*Call, error) {
+	opts = caller.callOpts(opts)
+	if err := caller.validate(calls); err != nil {
+		return nil, calls, err
+	}
+	resetCalls(calls)
+	var legacyCalls []contract.Multicall3Call
+	var calldataBytes int
+	for i, call := range calls {
+		if call.CanFail {
+			return nil, calls, fmt.Errorf("call at index [%d]: %w", i, ErrAllowFailureUnsupported)
+		}
+		b, err := call.Pack()
+		if err != nil {
+			return nil, calls, fmt.Errorf("failed to pack call inputs at index [%d]: %v", i, err)
+		}
+		legacyCalls = append(legacyCalls, contract.Multicall3Call{
+			Target:   call.Contract.address,
+			CallData: b,
+		})
+		calldataBytes += len(b)
+	}
+	if err := caller.checkRequestSize(len(legacyCalls), "aggregate", legacyCalls); err != nil {
+		return nil, calls, err
+	}
+	caller.observeEncoded(calldataBytes)
+
+	caller.logf("multicall: sending %d legacy calls", len(legacyCalls))
+	var result struct {
+		BlockNumber *big.Int
+		ReturnData  [][]byte
+	}
+	err := caller.retry(opts.Context, func() (err error) {
+		result, err = c.Aggregate(opts, legacyCalls)
+		return err
+	})
+	if err != nil {
+		caller.logf("multicall: failed: %v", err)
+		return nil, calls, multicallError(err)
+	}
+
+	var returnBytes int
+	for _, returnData := range result.ReturnData {
+		returnBytes += len(returnData)
+	}
+	caller.observeDecoded(returnBytes)
+
+	now := time.Now()
+	for i, returnData := range result.ReturnData {
+		call := calls[i] // index always matches
+		call.ReturnData = returnData
+		call.UpdatedAt = now
+		call.Failed = false
+		setCallFields(call, true)
+		if err := call.Unpack(returnData); err != nil {
+			return result.BlockNumber, calls, fmt.Errorf("failed to unpack call outputs at index [%d]: %v", i, err)
+		}
+		if caller.onResult != nil {
+			caller.onResult(call)
+		}
+	}
+	return result.BlockNumber, calls, nil
+}
+
+// validate checks all calls in strict mode and joins the errors.
+func (caller *Caller) validate(calls []*Call) error {
+	if !caller.strict {
+		return nil
+	}
+	var errs []error
+	for i, call := range calls {
+		if err := call.check(); err != nil {
+			errs = append(errs, fmt.Errorf("call '%s' at index [%d]: %v", call.label(), i, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid calls: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func packCalls(calls []*Call) ([]contract.Multicall3Call3, error) {
 	var multiCalls []contract.Multicall3Call3
 
 	for i, call := range calls {
 		b, err := call.Pack()
 		if err != nil {
-			return calls, fmt.Errorf("failed to pack call inputs at index [%d]: %v", i, err)
+			return nil, fmt.Errorf("failed to pack call inputs at index [%d]: %v", i, err)
 		}
 		multiCalls = append(multiCalls, contract.Multicall3Call3{
 			Target:       call.Contract.address,
@@ -94,40 +704,122 @@ func (caller *Caller) Call(opts *bind.CallOpts, calls ...*Call) ([]*Call, error)
 			CallData:     b,
 		})
 	}
+	return multiCalls, nil
+}
 
-	results, err := caller.contract.Aggregate3(opts, multiCalls)
-	if err != nil {
-		return calls, fmt.Errorf("multicall failed: %v", err)
-	}
-
+// unpackResults sets the results to the calls. Every call is unpacked, the error
+// of each is set to Call.Err and the first one is returned. onResult, if set,
+// is called with every call successfully decoded.
+func unpackResults(calls []*Call, results []contract.Multicall3Result, onResult func(*Call)) error {
+	now := time.Now()
+	var firstErr error
 	for i, result := range results {
 		call := calls[i] // index always matches
+		call.ReturnData = result.ReturnData
+		call.UpdatedAt = now
 		call.Failed = !result.Success
+		call.RevertReason = ""
+		call.CustomError = nil
+		call.Err = nil
+		setCallFields(call, result.Success)
 		if call.Failed {
+			call.RevertReason = revertReason(call.Contract, result.ReturnData)
+			call.CustomError = decodeCustomError(call.Contract, result.ReturnData)
+			call.Unexpected = false
+			call.Err = fmt.Errorf("%w: %s", ErrCallFailed, call.RevertReason)
+			if call.RevertInto != nil {
+				err := call.UnpackRevert(result.ReturnData)
+				if err != nil && !errors.Is(err, ErrUnknownRevert) {
+					call.Err = err
+					if firstErr == nil {
+						firstErr = fmt.Errorf("failed to unpack call revert at index [%d]: %v", i, err)
+					}
+				}
+			}
 			continue
 		}
 		if err := call.Unpack(result.ReturnData); err != nil {
-			return calls, fmt.Errorf("failed to unpack call outputs at index [%d]: %v", i, err)
+			call.Err = err
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to unpack call outputs at index [%d]: %v", i, err)
+			}
+			continue
+		}
+		if onResult != nil {
+			onResult(call)
 		}
 	}
-
-	return calls, nil
+	return firstErr
 }
 
 // CallChunked makes multiple multicalls by chunking given calls.
 // Cooldown is helpful for sleeping between chunks and avoiding rate limits.
+// A zero cooldown falls back to the caller default set with WithDefaultCooldown.
+// When the context is cancelled, it stops before the next chunk or during the
+// cooldown and returns the calls done so far with the context error.
 func (caller *Caller) CallChunked(opts *bind.CallOpts, chunkSize int, cooldown time.Duration, calls ...*Call) ([]*Call, error) {
+	return caller.callChunked(opts, chunkSize, cooldown, nil, calls)
+}
+
+// CallChunkedWithProgress is like CallChunked, and calls progress after each chunk
+// with the number of calls done so far and the total number of calls.
+// Progress is called synchronously from the chunk loop, never concurrently.
+func (caller *Caller) CallChunkedWithProgress(opts *bind.CallOpts, chunkSize int, cooldown time.Duration, progress func(done, total int), calls ...*Call) ([]*Call, error) {
+	return caller.callChunked(opts, chunkSize, cooldown, progress, calls)
+}
+
+func (caller *Caller) callChunked(opts *bind.CallOpts, chunkSize int, cooldown time.Duration, progress func(done, total int), calls []*Call) ([]*Call, error) {
+	if limit := caller.CallLimit(); limit > 0 && (chunkSize <= 0 || chunkSize > limit) {
+		chunkSize = limit
+	}
+	return caller.callChunks(opts, chunkInputs(chunkSize, calls), cooldown, 0, progress, calls)
+}
+
+// callChunks makes a multicall per chunk of the calls, one after the other.
+// A non-zero budget stops before a chunk expected to end after it.
+func (caller *Caller) callChunks(opts *bind.CallOpts, chunks [][]*Call, cooldown, budget time.Duration, progress func(done, total int), calls []*Call) ([]*Call, error) {
+	if cooldown == 0 {
+		cooldown = caller.defaultCooldown
+	}
+	opts = caller.callOpts(opts)
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	start := time.Now()
 	var allCalls []*Call
-	for i, chunk := range chunkInputs(chunkSize, calls) {
+	for i, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return allCalls, err
+		}
+		if budget > 0 && i > 0 {
+			elapsed := time.Since(start)
+			avgChunkLatency := (elapsed - time.Duration(i-1)*cooldown) / time.Duration(i)
+			if elapsed+cooldown+avgChunkLatency > budget {
+				return allCalls, fmt.Errorf("%w: %d of %d calls processed in %s", ErrBudgetExceeded, len(allCalls), len(calls), elapsed.Round(time.Millisecond))
+			}
+		}
 		if i > 0 && cooldown > 0 {
-			time.Sleep(cooldown)
+			timer := time.NewTimer(cooldown)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return allCalls, ctx.Err()
+			case <-timer.C:
+			}
 		}
 
 		ck, err := caller.Call(opts, chunk...)
 		if err != nil {
-			return calls, fmt.Errorf("call chunk [%d] failed: %v", i, err)
+			return allCalls, fmt.Errorf("call chunk [%d] failed: %w", i, err)
 		}
 		allCalls = append(allCalls, ck...)
+		if progress != nil {
+			progress(len(allCalls), len(calls))
+		}
+		if caller.progress != nil {
+			caller.progress(i, len(chunks), len(allCalls))
+		}
 	}
 	return allCalls, nil
 }
@@ -159,3 +851,39 @@ func chunkInputs[T any](chunkSize int, inputs []T) (chunks [][]T) {
 
 	return
 }
+
+// chunkCount returns the number of chunks chunkInputs splits the inputs into.
+func chunkCount(inputCount, chunkSize int) int {
+	if inputCount == 0 {
+		return 0
+	}
+	if chunkSize <= 0 || inputCount < 2 || chunkSize > inputCount {
+		return 1
+	}
+	return (inputCount + chunkSize - 1) / chunkSize
+}
+
+// EstimateDuration estimates how long CallChunked takes for the given number
+// of calls, from the average latency of a chunk and the cooldown between chunks.
+func EstimateDuration(totalCalls, chunkSize int, cooldown, avgChunkLatency time.Duration) time.Duration {
+	chunks := chunkCount(totalCalls, chunkSize)
+	if chunks == 0 {
+		return 0
+	}
+	return time.Duration(chunks)*avgChunkLatency + time.Duration(chunks-1)*cooldown
+}
+
+// ErrBudgetExceeded is returned by CallChunkedBudget when the remaining chunks
+// would not complete within the budget.
+var ErrBudgetExceeded = errors.New("call budget exceeded")
+
+// CallChunkedBudget is like CallChunked, but stops before a chunk expected to
+// end after maxTotal since the start, from the average latency of the chunks
+// done so far and the cooldown. The first chunk always runs. It returns the calls
+// done so far along with ErrBudgetExceeded, telling how many calls were processed.
+func (caller *Caller) CallChunkedBudget(opts *bind.CallOpts, chunkSize int, cooldown, maxTotal time.Duration, calls ...*Call) ([]*Call, error) {
+	if limit := caller.CallLimit(); limit > 0 && (chunkSize <= 0 || chunkSize > limit) {
+		chunkSize = limit
+	}
+	return caller.callChunks(opts, chunkInputs(chunkSize, calls), cooldown, maxTotal, nil, calls)
+}
@@ -0,0 +1,377 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCallVia(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+
+	first := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	second := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	for _, address := range []common.Address{first, second, first} {
+		balance := new(big.Int)
+		if _, err := caller.CallVia(address, nil, c.NewCall(balance, "balanceOf", ownerAddress)); err != nil {
+			t.Fatal(err)
+		}
+		if balance.Int64() != 0xbb {
+			t.Fatalf("unexpected balance %s", balance)
+		}
+	}
+	if _, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []common.Address{first, second, first, common.HexToAddress(DefaultAddress)}
+	for i, msg := range chain.msgs {
+		if *msg.To != want[i] {
+			t.Fatalf("multicall %d: expected %s, got %s", i, want[i], msg.To)
+		}
+	}
+}
+
+func TestStrictValidation(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+
+	type tooMany struct {
+		A, B, C, D *big.Int
+	}
+	tests := []struct {
+		name  string
+		call  *Call
+		valid bool
+	}{
+		{"valid", c.NewCall(new(big.Int), "balanceOf", ownerAddress), true},
+		{"unknown method", c.NewCall(new(big.Int), "totalSupply"), false},
+		{"missing input", c.NewCall(new(big.Int), "balanceOf"), false},
+		{"wrong input type", c.NewCall(new(big.Int), "balanceOf", "owner"), false},
+		{"too many fields", c.NewCall(new(tooMany), "getReserves"), false},
+		{"fewer fields", c.NewCall(new(struct{ Reserve0 *big.Int }), "getReserves"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newFakeChain(t)
+			caller := newTestCaller(t, chain, WithStrictValidation())
+			_, err := caller.Call(nil, tt.call.Name(tt.name))
+			if tt.valid != (err == nil) {
+				t.Fatalf("expected valid %v, got %v", tt.valid, err)
+			}
+			if !tt.valid && chain.calls() != 0 {
+				t.Fatal("expected no eth_call for an invalid call")
+			}
+		})
+	}
+
+	// all offending calls are reported together
+	caller := newTestCaller(t, newFakeChain(t), WithStrictValidation())
+	_, err := caller.Call(nil,
+		c.NewCall(new(big.Int), "totalSupply").Name("first"),
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress),
+		c.NewCall(new(big.Int), "balanceOf").Name("second"),
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, part := range []string{"'first' at index [0]", "'second' at index [2]"} {
+		if !strings.Contains(err.Error(), part) {
+			t.Fatalf("expected %q in %v", part, err)
+		}
+	}
+}
+
+func TestDefaultCooldown(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	const cooldown = 40 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		cooldown time.Duration
+		slow     bool
+	}{
+		{"default", 0, true},
+		{"explicit", time.Millisecond, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newFakeChain(t)
+			caller := newTestCaller(t, chain, WithDefaultCooldown(cooldown))
+			start := time.Now()
+			if _, err := caller.CallChunked(nil, 2, tt.cooldown, balanceCalls(c, 6)...); err != nil {
+				t.Fatal(err)
+			}
+			elapsed := time.Since(start)
+			if tt.slow != (elapsed >= 2*cooldown) {
+				t.Fatalf("expected slow %v, took %s", tt.slow, elapsed)
+			}
+			if sizes := chain.sizes(); len(sizes) != 3 {
+				t.Fatalf("expected 3 chunks, got %v", sizes)
+			}
+		})
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	derived := caller.WithContext(ctx)
+	if _, err := derived.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	if _, err := derived.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation, got %v", err)
+	}
+	if _, err := derived.CallChunked(nil, 1, 0, balanceCalls(c, 2)...); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation of the chunks, got %v", err)
+	}
+	// a cancellation during a chunk returns only the chunks done
+	ctx, cancel = context.WithCancel(context.Background())
+	second := chain.calls() + 1
+	chain.fail = func(n int, calls []subCall) error {
+		if n == second {
+			cancel()
+			return ctx.Err()
+		}
+		return nil
+	}
+	done, err := caller.WithContext(ctx).CallChunked(nil, 3, 0, balanceCalls(c, 9)...)
+	if !errors.Is(err, context.Canceled) || len(done) != 3 {
+		t.Fatalf("expected the first chunk and the cancellation, got %d calls: %v", len(done), err)
+	}
+	chain.fail = nil
+	// the original caller is not modified
+	if _, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+	// a context set on the options takes precedence
+	if _, err := derived.Call(&bind.CallOpts{Context: context.Background()}, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEstimateDuration(t *testing.T) {
+	tests := []struct {
+		name       string
+		totalCalls int
+		chunkSize  int
+		want       time.Duration
+	}{
+		{"no calls", 0, 10, 0},
+		{"single chunk", 5, 10, 100 * time.Millisecond},
+		{"exact chunks", 30, 10, 3*100*time.Millisecond + 2*time.Second},
+		{"remainder", 31, 10, 4*100*time.Millisecond + 3*time.Second},
+		{"unchunked", 31, 0, 100 * time.Millisecond},
+		{"chunks of one", 3, 1, 3*100*time.Millisecond + 2*time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EstimateDuration(tt.totalCalls, tt.chunkSize, time.Second, 100*time.Millisecond); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCallSuccessField(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+
+	type result struct {
+		Balance *big.Int
+		Success bool `abi:"success"`
+	}
+	ok := c.NewCall(new(result), "balanceOf", ownerAddress).AllowFailure()
+	failed := c.NewCall(new(result), "fail").AllowFailure()
+	if _, err := caller.Call(nil, ok, failed); err != nil {
+		t.Fatal(err)
+	}
+	if got := ok.Outputs.(*result); !got.Success || got.Balance.Int64() != 0xbb {
+		t.Fatalf("unexpected result %+v", got)
+	}
+	if got := failed.Outputs.(*result); got.Success || got.Balance != nil {
+		t.Fatalf("unexpected failed result %+v", got)
+	}
+}
+
+func TestOnResult(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	calls := balanceCalls(c, 5)
+	calls[2] = c.NewCall(new(big.Int), "fail").AllowFailure()
+
+	var got []*Call
+	caller := newTestCaller(t, newFakeChain(t), WithOnResult(func(call *Call) {
+		if call.UpdatedAt.IsZero() {
+			t.Error("called before the call is decoded")
+		}
+		got = append(got, call)
+	}))
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	want := []*Call{calls[0], calls[1], calls[3], calls[4]}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d invocations, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("invocation %d: unexpected call %s", i, got[i].label())
+		}
+	}
+}
+
+func TestCallChunkedBudget(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+
+	calls := balanceCalls(c, 10)
+	done, err := caller.CallChunkedBudget(nil, 3, 20*time.Millisecond, 30*time.Millisecond, calls...)
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+	}
+	if len(done) == 0 || len(done) >= len(calls) || len(done)%3 != 0 {
+		t.Fatalf("expected some full chunks done, got %d calls", len(done))
+	}
+	if !strings.Contains(err.Error(), fmt.Sprintf("%d of %d calls processed", len(done), len(calls))) {
+		t.Fatalf("expected processed count in error, got %v", err)
+	}
+	if chain.calls() != len(done)/3 {
+		t.Fatalf("expected %d multicalls, got %d", len(done)/3, chain.calls())
+	}
+	for i, call := range done {
+		if call.Outputs.(*big.Int).Int64() != int64(i) {
+			t.Fatalf("call %d: unexpected balance %s", i, call.Outputs)
+		}
+	}
+
+	done, err = caller.CallChunkedBudget(nil, 3, time.Millisecond, time.Minute, balanceCalls(c, 10)...)
+	if err != nil || len(done) != 10 {
+		t.Fatalf("expected all calls within a large budget, got %d: %v", len(done), err)
+	}
+}
+
+func TestCallTargetField(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	other := mustContract(t, testABI, ownerAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+
+	type result struct {
+		Balance *big.Int
+		Target  common.Address `abi:"target"`
+		Success bool           `abi:"success"`
+	}
+	calls := []*Call{
+		c.NewCall(new(result), "balanceOf", ownerAddress),
+		other.NewCall(new(result), "balanceOf", tokenAddress),
+		other.NewCall(new(result), "fail").AllowFailure(),
+	}
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []common.Address{tokenAddress, ownerAddress, ownerAddress} {
+		if got := calls[i].Outputs.(*result); got.Target != want {
+			t.Fatalf("call %d: expected target %s, got %s", i, want, got.Target)
+		}
+	}
+	if got := calls[1].Outputs.(*result); !got.Success || got.Balance.Int64() != 0xaa {
+		t.Fatalf("unexpected result %+v", got)
+	}
+	if got := calls[2].Outputs.(*result); got.Success {
+		t.Fatalf("unexpected failed result %+v", got)
+	}
+}
+
+func TestRetryFailedAtBlock(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	// balanceOf reverts at the latest block, as during a reorg
+	chain.handle = func(block *big.Int, call subCall) (bool, []byte) {
+		if method, _ := c.abi.MethodById(call.Data); block == nil && method != nil && method.Name == "balanceOf" {
+			return false, revertData("reorg")
+		}
+		return handleTestCall(t, c, call)
+	}
+	caller := newTestCaller(t, chain)
+
+	calls := []*Call{
+		c.NewCall(new(string), "name"),
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress).AllowFailure(),
+		c.NewCall(new(big.Int), "fail").AllowFailure(),
+	}
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	if !calls[1].Failed || calls[1].RevertReason != "reorg" {
+		t.Fatalf("expected balanceOf to fail at the latest block, got %q", calls[1].RevertReason)
+	}
+
+	got, err := caller.RetryFailedAtBlock(nil, calls, big.NewInt(99))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(calls) {
+		t.Fatalf("expected %d calls, got %d", len(calls), len(got))
+	}
+	if calls[1].Failed || calls[1].Err != nil || calls[1].Outputs.(*big.Int).Int64() != 0xbb {
+		t.Fatalf("expected balanceOf to succeed at block 99, got %v", calls[1].Err)
+	}
+	if !calls[2].Failed || calls[2].RevertReason != "boom" {
+		t.Fatalf("expected fail to keep failing, got %q", calls[2].RevertReason)
+	}
+	if n := chain.calls(); n != 2 || len(chain.sent[1]) != 2 || chain.blocks[1].Int64() != 99 {
+		t.Fatalf("expected only the failed calls retried at block 99, got %d multicalls", n)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain, WithPinnedBlock(big.NewInt(42)))
+
+	calls := balanceCalls(c, 3)
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := caller.Refresh(nil, calls, nil); err == nil {
+		t.Fatal("expected error for a nil predicate")
+	}
+
+	got, err := caller.Refresh(nil, calls, func(call *Call) bool {
+		return call != calls[1]
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(calls) {
+		t.Fatalf("expected %d calls, got %d", len(calls), len(got))
+	}
+	if n := chain.calls(); n != 2 || len(chain.sent[1]) != 2 {
+		t.Fatalf("expected the 2 stale calls refreshed in a multicall, got %d multicalls", n)
+	}
+	// the refresh reads the latest block instead of the pinned one
+	if chain.blocks[0].Int64() != 42 || chain.blocks[1] != nil {
+		t.Fatalf("expected the pinned block then the latest, got %v", chain.blocks)
+	}
+
+	if _, err := caller.Refresh(BlockCallOpts(big.NewInt(43)), calls, func(*Call) bool { return true }); err != nil {
+		t.Fatal(err)
+	}
+	if chain.blocks[2].Int64() != 43 {
+		t.Fatalf("expected the block of the options, got %s", chain.blocks[2])
+	}
+}
@@ -0,0 +1,27 @@
+package multicall
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// LatestCallOpts returns call options reading at the latest block.
+func LatestCallOpts() *bind.CallOpts {
+	return &bind.CallOpts{}
+}
+
+// BlockCallOpts returns call options reading at the given block, or at the
+// latest block when n is nil.
+func BlockCallOpts(n *big.Int) *bind.CallOpts {
+	if n == nil {
+		return LatestCallOpts()
+	}
+	return &bind.CallOpts{BlockNumber: new(big.Int).Set(n)}
+}
+
+// PendingCallOpts returns call options reading the pending state.
+// The pending flag takes precedence over any block number.
+func PendingCallOpts() *bind.CallOpts {
+	return &bind.CallOpts{Pending: true}
+}
@@ -0,0 +1,40 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestCallOpts(t *testing.T) {
+	if opts := LatestCallOpts(); opts.BlockNumber != nil || opts.Pending {
+		t.Fatalf("unexpected latest opts %+v", opts)
+	}
+	if opts := PendingCallOpts(); !opts.Pending || opts.BlockNumber != nil {
+		t.Fatalf("unexpected pending opts %+v", opts)
+	}
+
+	n := big.NewInt(100)
+	opts := BlockCallOpts(n)
+	if opts.Pending || opts.BlockNumber.Cmp(n) != 0 {
+		t.Fatalf("unexpected block opts %+v", opts)
+	}
+	n.SetInt64(200)
+	if opts.BlockNumber.Int64() != 100 {
+		t.Fatal("expected the block number to be copied")
+	}
+	if opts := BlockCallOpts(nil); opts.BlockNumber != nil || opts.Pending {
+		t.Fatalf("expected latest opts for a nil block, got %+v", opts)
+	}
+}
+
+func TestCallAtBlockOpts(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+	if _, err := caller.Call(BlockCallOpts(big.NewInt(42)), c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+	if chain.blocks[0] == nil || chain.blocks[0].Int64() != 42 {
+		t.Fatalf("expected the call at block 42, got %v", chain.blocks[0])
+	}
+}
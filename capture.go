@@ -0,0 +1,66 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// captureTransport is an http.RoundTripper passing the raw JSON-RPC request
+// and response bodies to a capture function.
+type captureTransport struct {
+	base    http.RoundTripper
+	capture func(reqBody, respBody []byte)
+}
+
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.capture(reqBody, nil)
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	t.capture(reqBody, respBody)
+	return resp, nil
+}
+
+// dialWithCapture dials an HTTP RPC endpoint capturing the raw traffic.
+// Other transports are dialed without capture.
+func dialWithCapture(ctx context.Context, rawURL string, capture func(reqBody, respBody []byte)) (*ethclient.Client, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var rpcOpts []rpc.ClientOption
+	if strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") {
+		rpcOpts = append(rpcOpts, rpc.WithHTTPClient(&http.Client{
+			Transport: &captureTransport{base: http.DefaultTransport, capture: capture},
+		}))
+	}
+	rpcClient, err := rpc.DialOptions(ctx, rawURL, rpcOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rpcClient), nil
+}
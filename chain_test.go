@@ -0,0 +1,310 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pinealctx/multicall/contract"
+)
+
+// testABI is the ABI of the contract most tests call.
+const testABI = `[
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]},
+	{"type":"function","name":"getReserves","stateMutability":"view","inputs":[],"outputs":[{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},{"name":"blockTimestampLast","type":"uint32"}]},
+	{"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"ping","stateMutability":"view","inputs":[],"outputs":[]},
+	{"type":"function","name":"fail","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var (
+	tokenAddress = common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	ownerAddress = common.HexToAddress("0x00000000000000000000000000000000000000bb")
+)
+
+// revertError is the error of an eth_call reverting with data, as returned by
+// go-ethereum clients.
+type revertError struct {
+	data []byte
+}
+
+func (e *revertError) Error() string {
+	return "execution reverted"
+}
+
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+func (e *revertError) ErrorData() any {
+	return hexutil.Encode(e.data)
+}
+
+// subCall is a call made within a multicall.
+type subCall struct {
+	Target common.Address
+	Data   []byte
+	Value  *big.Int
+}
+
+// fakeChain is a bind.ContractCaller running multicalls in memory: every call
+// of a multicall is answered by handle.
+type fakeChain struct {
+	// handle returns the success and the return data, or revert data, of a call.
+	handle func(block *big.Int, call subCall) (bool, []byte)
+	// fail, if set, returns the error of the n-th eth_call, counting from 0.
+	fail func(n int, calls []subCall) error
+	// methods, if set, lists the only multicall methods the contract has,
+	// calls of other methods revert.
+	methods []string
+
+	mu     sync.Mutex
+	msgs   []ethereum.CallMsg
+	blocks []*big.Int
+	sent   [][]subCall
+}
+
+// newFakeChain returns a chain where every call to the test contract succeeds.
+func newFakeChain(t testing.TB) *fakeChain {
+	c := mustContract(t, testABI, tokenAddress)
+	return &fakeChain{handle: func(block *big.Int, call subCall) (bool, []byte) {
+		return handleTestCall(t, c, call)
+	}}
+}
+
+// handleTestCall answers a call to the test contract: balanceOf returns the
+// last byte of the owner address, fail reverts with "boom".
+func handleTestCall(t testing.TB, c *Contract, call subCall) (bool, []byte) {
+	method, err := c.abi.MethodById(call.Data)
+	if err != nil {
+		return false, nil
+	}
+	switch method.Name {
+	case "balanceOf":
+		args, err := method.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return false, nil
+		}
+		owner := args[0].(common.Address)
+		return true, pack(t, c, "balanceOf", big.NewInt(int64(owner[19])))
+	case "getReserves":
+		return true, pack(t, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+	case "name":
+		return true, pack(t, c, "name", "Token")
+	case "ping":
+		return true, nil
+	default:
+		return false, revertData("boom")
+	}
+}
+
+func (c *fakeChain) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return []byte{0x60}, nil
+}
+
+func (c *fakeChain) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if ctx != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	multicallABI, err := contract.MulticallMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	method, err := multicallABI.MethodById(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	if c.methods != nil && !slices.Contains(c.methods, method.Name) {
+		return nil, &revertError{}
+	}
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	var calls []subCall
+	switch method.Name {
+	case "aggregate3":
+		for _, call := range *abi.ConvertType(args[0], new([]contract.Multicall3Call3)).(*[]contract.Multicall3Call3) {
+			calls = append(calls, subCall{Target: call.Target, Data: call.CallData})
+		}
+	case "aggregate3Value":
+		for _, call := range *abi.ConvertType(args[0], new([]contract.Multicall3Call3Value)).(*[]contract.Multicall3Call3Value) {
+			calls = append(calls, subCall{Target: call.Target, Data: call.CallData, Value: call.Value})
+		}
+	case "aggregate":
+		for _, call := range *abi.ConvertType(args[0], new([]contract.Multicall3Call)).(*[]contract.Multicall3Call) {
+			calls = append(calls, subCall{Target: call.Target, Data: call.CallData})
+		}
+	case "tryAggregate", "tryBlockAndAggregate":
+		for _, call := range *abi.ConvertType(args[1], new([]contract.Multicall3Call)).(*[]contract.Multicall3Call) {
+			calls = append(calls, subCall{Target: call.Target, Data: call.CallData})
+		}
+	}
+
+	c.mu.Lock()
+	n := len(c.msgs)
+	c.msgs = append(c.msgs, msg)
+	c.blocks = append(c.blocks, blockNumber)
+	c.sent = append(c.sent, calls)
+	c.mu.Unlock()
+	if c.fail != nil {
+		if err := c.fail(n, calls); err != nil {
+			return nil, err
+		}
+	}
+
+	block := blockNumber
+	if block == nil {
+		block = big.NewInt(100)
+	}
+	var results []contract.Multicall3Result
+	for _, call := range calls {
+		success, data := c.handle(blockNumber, call)
+		results = append(results, contract.Multicall3Result{Success: success, ReturnData: data})
+	}
+
+	switch method.Name {
+	case "aggregate3", "aggregate3Value":
+		allowFailure := func(i int) bool {
+			if method.Name == "aggregate3" {
+				return (*abi.ConvertType(args[0], new([]contract.Multicall3Call3)).(*[]contract.Multicall3Call3))[i].AllowFailure
+			}
+			return (*abi.ConvertType(args[0], new([]contract.Multicall3Call3Value)).(*[]contract.Multicall3Call3Value))[i].AllowFailure
+		}
+		for i, result := range results {
+			if !result.Success && !allowFailure(i) {
+				return nil, &revertError{data: revertData("Multicall3: call failed")}
+			}
+		}
+		return method.Outputs.Pack(results)
+	case "aggregate":
+		var returnData [][]byte
+		for _, result := range results {
+			if !result.Success {
+				return nil, &revertError{data: revertData("Multicall3: call failed")}
+			}
+			returnData = append(returnData, result.ReturnData)
+		}
+		return method.Outputs.Pack(block, returnData)
+	case "tryAggregate", "tryBlockAndAggregate":
+		if args[0].(bool) {
+			for _, result := range results {
+				if !result.Success {
+					return nil, &revertError{data: revertData("Multicall3: call failed")}
+				}
+			}
+		}
+		if method.Name == "tryAggregate" {
+			return method.Outputs.Pack(results)
+		}
+		return method.Outputs.Pack(block, [32]byte{}, results)
+	case "getEthBalance":
+		return method.Outputs.Pack(big.NewInt(1e18))
+	case "getBlockNumber":
+		return method.Outputs.Pack(block)
+	default:
+		return method.Outputs.Pack(big.NewInt(1))
+	}
+}
+
+// calls returns the number of eth_calls sent.
+func (c *fakeChain) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.msgs)
+}
+
+// methodsSent returns the multicall method of every eth_call sent.
+func (c *fakeChain) methodsSent(t testing.TB) []string {
+	t.Helper()
+	multicallABI, err := contract.MulticallMetaData.GetAbi()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var methods []string
+	for _, msg := range c.msgs {
+		method, err := multicallABI.MethodById(msg.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		methods = append(methods, method.Name)
+	}
+	return methods
+}
+
+// sizes returns the number of calls of every multicall sent.
+func (c *fakeChain) sizes() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sizes []int
+	for _, calls := range c.sent {
+		sizes = append(sizes, len(calls))
+	}
+	return sizes
+}
+
+func newTestCaller(t testing.TB, client *fakeChain, fns ...Option) *Caller {
+	t.Helper()
+	caller, err := New(append([]Option{WithClient(client)}, fns...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return caller
+}
+
+func mustContract(t testing.TB, abiJSON string, address common.Address, fns ...ContractOption) *Contract {
+	t.Helper()
+	c, err := NewContract(append([]ContractOption{WithABIJSON(abiJSON), WithAddress(address)}, fns...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// pack packs values as the outputs of the method.
+func pack(t testing.TB, c *Contract, method string, values ...any) []byte {
+	t.Helper()
+	b, err := c.abi.Methods[method].Outputs.Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// revertData returns the revert data of Error(reason).
+func revertData(reason string) []byte {
+	args := abi.Arguments{{Type: mustType("string")}}
+	b, err := args.Pack(reason)
+	if err != nil {
+		panic(err)
+	}
+	return append(hexutil.MustDecode("0x08c379a0"), b...)
+}
+
+func mustType(t string) abi.Type {
+	typ, err := abi.NewType(t, "", nil)
+	if err != nil {
+		panic(fmt.Sprintf("invalid type %s: %v", t, err))
+	}
+	return typ
+}
+
+// balanceCalls returns n balanceOf calls to the test contract.
+func balanceCalls(c *Contract, n int) []*Call {
+	calls := make([]*Call, n)
+	for i := range calls {
+		owner := common.BigToAddress(big.NewInt(int64(i)))
+		calls[i] = c.NewCall(new(big.Int), "balanceOf", owner)
+	}
+	return calls
+}
@@ -0,0 +1,98 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// ParallelOptions controls how CallParallel runs chunks.
+type ParallelOptions struct {
+	// Concurrency is the maximum number of chunks in flight. Defaults to 1.
+	Concurrency int
+	// FailFast cancels the chunks not yet finished on the first chunk error and
+	// returns that error. Otherwise all chunks run and their errors are joined
+	// in chunk order.
+	FailFast bool
+}
+
+// CallConcurrent makes multiple multicalls by chunking given calls, like CallChunked,
+// but runs up to concurrency chunks in parallel. Each call belongs to a single chunk
+// so calls are never shared between goroutines. The first chunk error cancels the
+// chunks not yet finished and is returned. Calls are returned in the given order.
+func (caller *Caller) CallConcurrent(opts *bind.CallOpts, chunkSize, concurrency int, calls ...*Call) ([]*Call, error) {
+	return caller.CallParallel(opts, chunkSize, ParallelOptions{Concurrency: concurrency, FailFast: true}, calls...)
+}
+
+// CallParallel is like CallConcurrent but lets the caller choose between failing fast
+// and running all chunks to completion.
+func (caller *Caller) CallParallel(opts *bind.CallOpts, chunkSize int, parallel ParallelOptions, calls ...*Call) ([]*Call, error) {
+	err := runParallel(caller.callOpts(opts), chunkInputs(chunkSize, calls), parallel, func(opts *bind.CallOpts, chunk []*Call) error {
+		_, err := caller.Call(opts, chunk...)
+		return err
+	})
+	return calls, err
+}
+
+// runParallel runs call for each chunk, up to parallel.Concurrency at a time,
+// with a copy of opts whose context is cancelled on failing fast.
+func runParallel(opts *bind.CallOpts, chunks [][]*Call, parallel ParallelOptions, call func(opts *bind.CallOpts, chunk []*Call) error) error {
+	parent := opts.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	concurrency := parallel.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		errs     = make([]error, len(chunks))
+	)
+	sem := make(chan struct{}, concurrency)
+	for i, chunk := range chunks {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, chunk []*Call) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			chunkOpts := *opts
+			chunkOpts.Context = ctx
+			if err := call(&chunkOpts, chunk); err != nil {
+				err = fmt.Errorf("call chunk [%d] failed: %v", i, err)
+				errs[i] = err
+				if parallel.FailFast {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	return parent.Err()
+}
@@ -0,0 +1,138 @@
+package multicall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+)
+
+// failChunkWith returns a fail function failing the multicalls containing the call.
+func failChunkWith(t *testing.T, call *Call) func(n int, calls []subCall) error {
+	data, err := call.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return func(n int, calls []subCall) error {
+		for _, call := range calls {
+			if bytes.Equal(call.Data, data) {
+				return errors.New("internal error")
+			}
+		}
+		return nil
+	}
+}
+
+// blockingChain is a fakeChain whose multicalls block until their context is
+// done, except the one containing the failing calldata, which fails once the
+// others are in flight.
+type blockingChain struct {
+	*fakeChain
+	failData []byte
+	blocked  int
+	started  chan struct{}
+
+	mu   sync.Mutex
+	sent int
+	errs []error
+}
+
+func (c *blockingChain) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	c.mu.Lock()
+	c.sent++
+	c.mu.Unlock()
+	if bytes.Contains(msg.Data, c.failData) {
+		for i := 0; i < c.blocked; i++ {
+			<-c.started
+		}
+		return nil, errors.New("internal error")
+	}
+	c.started <- struct{}{}
+	<-ctx.Done()
+	c.mu.Lock()
+	c.errs = append(c.errs, ctx.Err())
+	c.mu.Unlock()
+	return nil, ctx.Err()
+}
+
+func TestCallParallelFailFast(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	calls := balanceCalls(c, 10)
+	failData, err := calls[4].Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// chunks [0] and [1] are in flight when chunk [2] fails
+	chain := &blockingChain{fakeChain: newFakeChain(t), failData: failData, blocked: 2, started: make(chan struct{}, 2)}
+	caller, err := New(WithClient(chain))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = caller.CallParallel(nil, 2, ParallelOptions{Concurrency: 3, FailFast: true}, calls...)
+	if err == nil || !strings.Contains(err.Error(), "chunk [2]") {
+		t.Fatalf("expected the error of chunk [2], got %v", err)
+	}
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	// the chunks in flight are cancelled and the others are never sent
+	if len(chain.errs) != 2 {
+		t.Fatalf("expected 2 cancelled chunks, got %d", len(chain.errs))
+	}
+	for _, err := range chain.errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("expected a cancelled context, got %v", err)
+		}
+	}
+	if chain.sent != 3 {
+		t.Fatalf("expected 3 multicalls, got %d", chain.sent)
+	}
+}
+
+func TestCallParallelCompleteAll(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	calls := balanceCalls(c, 10)
+	chain := newFakeChain(t)
+	chain.fail = failChunkWith(t, calls[4])
+	caller := newTestCaller(t, chain)
+
+	_, err := caller.CallParallel(nil, 2, ParallelOptions{Concurrency: 3}, calls...)
+	if err == nil || !strings.Contains(err.Error(), "chunk [2]") {
+		t.Fatalf("expected the error of chunk [2], got %v", err)
+	}
+	if got := chain.calls(); got != 5 {
+		t.Fatalf("expected all 5 multicalls, got %d", got)
+	}
+	for i, call := range calls {
+		if i == 4 || i == 5 {
+			continue
+		}
+		if balance := call.Outputs.(*big.Int); balance.Int64() != int64(i) {
+			t.Fatalf("call %d: unexpected balance %s", i, balance)
+		}
+	}
+}
+
+func TestCallConcurrent(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	calls := balanceCalls(c, 50)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+
+	if _, err := caller.CallConcurrent(nil, 5, 4, calls...); err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.calls(); got != 10 {
+		t.Fatalf("expected 10 multicalls, got %d", got)
+	}
+	for i, call := range calls {
+		if balance := call.Outputs.(*big.Int); balance.Int64() != int64(i) {
+			t.Fatalf("call %d: unexpected balance %s", i, balance)
+		}
+	}
+}
@@ -1,10 +1,28 @@
 package contract
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // Interface is an abstraction of the contract.
 type Interface interface {
+	Aggregate(opts *bind.CallOpts, calls []Multicall3Call) (struct {
+		BlockNumber *big.Int
+		ReturnData  [][]byte
+	}, error)
 	Aggregate3(opts *bind.CallOpts, calls []Multicall3Call3) ([]Multicall3Result, error)
+	TryAggregate(opts *bind.CallOpts, requireSuccess bool, calls []Multicall3Call) ([]Multicall3Result, error)
+	TryBlockAndAggregate(opts *bind.CallOpts, requireSuccess bool, calls []Multicall3Call) (struct {
+		BlockNumber *big.Int
+		BlockHash   [32]byte
+		ReturnData  []Multicall3Result
+	}, error)
+	GetEthBalance(opts *bind.CallOpts, addr common.Address) (*big.Int, error)
+	GetBlockNumber(opts *bind.CallOpts) (*big.Int, error)
+	GetCurrentBlockTimestamp(opts *bind.CallOpts) (*big.Int, error)
+	GetBasefee(opts *bind.CallOpts) (*big.Int, error)
+	GetChainId(opts *bind.CallOpts) (*big.Int, error)
 }
@@ -0,0 +1,632 @@
+package multicall
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// tagName is the struct tag key used to customize how outputs are decoded
+// into the fields of an output struct.
+const tagName = "abi"
+
+var (
+	typeMappersMu sync.RWMutex
+	typeMappers   = make(map[reflect.Type]func(any) (any, error))
+)
+
+// RegisterTypeMapper registers a function converting unpacked output values
+// into the given Go type. Output fields of that type are set from the mapper
+// result instead of the default conversion. The mapper must return a value
+// assignable to the type.
+func RegisterTypeMapper(goType reflect.Type, fn func(any) (any, error)) {
+	typeMappersMu.Lock()
+	defer typeMappersMu.Unlock()
+	typeMappers[goType] = fn
+}
+
+func lookupTypeMapper(goType reflect.Type) (func(any) (any, error), bool) {
+	typeMappersMu.RLock()
+	defer typeMappersMu.RUnlock()
+	fn, ok := typeMappers[goType]
+	return fn, ok
+}
+
+// OutputUnmarshaler is implemented by outputs decoding the unpacked values of
+// the method outputs themselves, instead of having their fields set by reflection.
+type OutputUnmarshaler interface {
+	UnmarshalABI(out []any) error
+}
+
+// fieldTag holds the decoding options of an output struct field.
+type fieldTag struct {
+	// pad allows a shorter dynamic array to fill a fixed-size array,
+	// leaving the remaining elements zero.
+	pad bool
+	// truncate allows a longer dynamic array to fill a fixed-size array,
+	// dropping the extra elements.
+	truncate bool
+	// text sets a field implementing encoding.TextUnmarshaler from
+	// the text representation of the output.
+	text bool
+	// boolish sets a bool field from a 0 or 1 integer output.
+	boolish bool
+	// str sets a string field from a bytes or bytesN output as text,
+	// trimming the zero padding, instead of hex encoding it.
+	str bool
+	// success sets a bool field from the success flag of the call
+	// instead of an output.
+	success bool
+	// target sets a common.Address field from the target contract of the call
+	// instead of an output.
+	target bool
+	// skip leaves the field unset, tagged "-".
+	skip bool
+	// timeLayout is the layout parsing a string output into a time.Time field,
+	// set with "time:<layout>". It must be the last option as the layout may
+	// contain commas. Defaults to time.RFC3339.
+	timeLayout string
+}
+
+func parseFieldTag(field reflect.StructField) fieldTag {
+	var tag fieldTag
+	if field.Tag.Get(tagName) == "-" {
+		tag.skip = true
+		return tag
+	}
+	opts := strings.Split(field.Tag.Get(tagName), ",")
+	for i, opt := range opts {
+		if layout, ok := strings.CutPrefix(strings.TrimSpace(opt), "time:"); ok {
+			tag.timeLayout = strings.Join(append([]string{layout}, opts[i+1:]...), ",")
+			break
+		}
+		switch strings.TrimSpace(opt) {
+		case "pad":
+			tag.pad = true
+		case "truncate":
+			tag.truncate = true
+		case "text":
+			tag.text = true
+		case "boolish":
+			tag.boolish = true
+		case "string":
+			tag.str = true
+		case "success":
+			tag.success = true
+		case "target":
+			tag.target = true
+		}
+	}
+	return tag
+}
+
+// decodePlan maps unpacked output values to the fields of a struct type.
+type decodePlan struct {
+	// flatten decodes the components of a single tuple output
+	// instead of the outputs.
+	flatten bool
+	// indexes holds the output index of each field, -1 for fields not decoded
+	// from an output.
+	indexes []int
+	// tags holds the parsed tag of each field.
+	tags []fieldTag
+	// decoded is the number of fields decoded from the outputs.
+	decoded int
+}
+
+type decodePlanKey struct {
+	method string
+	typ    reflect.Type
+}
+
+// methodDecodePlan returns the decode plan of the method outputs into the
+// struct type, cached by the contract after first use.
+func (contract *Contract) methodDecodePlan(method string, typ reflect.Type) *decodePlan {
+	key := decodePlanKey{method: method, typ: typ}
+	if plan, ok := contract.plans.Load(key); ok {
+		return plan.(*decodePlan)
+	}
+
+	plan := newDecodePlan(contract.abi.Methods[method].Outputs, typ, contract.matchByName)
+	actual, _ := contract.plans.LoadOrStore(key, plan)
+	return actual.(*decodePlan)
+}
+
+// fieldTags returns the parsed tags of the fields of the struct type,
+// cached by the contract after first use.
+func (contract *Contract) fieldTags(typ reflect.Type) []fieldTag {
+	if tags, ok := contract.tags.Load(typ); ok {
+		return tags.([]fieldTag)
+	}
+	actual, _ := contract.tags.LoadOrStore(typ, parseFieldTags(typ))
+	return actual.([]fieldTag)
+}
+
+// newDecodePlan computes the decode plan of the outputs into the struct type.
+func newDecodePlan(outputs abi.Arguments, typ reflect.Type, matchByName bool) *decodePlan {
+	plan := &decodePlan{tags: parseFieldTags(typ)}
+	plan.decoded = decodedFields(plan.tags)
+	var names []string
+	if matchByName {
+		for _, output := range outputs {
+			names = append(names, output.Name)
+		}
+	}
+	// A single tuple output whose components match the struct fields is
+	// decoded component by component.
+	if len(outputs) == 1 && outputs[0].Type.T == abi.TupleTy &&
+		plan.decoded >= 2 && plan.decoded == len(outputs[0].Type.TupleElems) {
+		plan.flatten = true
+		names = nil
+		if matchByName {
+			names = outputs[0].Type.TupleRawNames
+		}
+	}
+	// Fields matching an output name claim it first, the other fields are
+	// given the unclaimed outputs in order, so that no output is decoded twice.
+	plan.indexes = make([]int, typ.NumField())
+	claimed := make([]bool, len(names))
+	var unmatched []int
+	for i := range plan.indexes {
+		plan.indexes[i] = -1
+		if !plan.tags[i].decoded() {
+			continue
+		}
+		if index := outputIndex(typ.Field(i).Name, names, claimed); index >= 0 {
+			plan.indexes[i] = index
+			claimed[index] = true
+			continue
+		}
+		unmatched = append(unmatched, i)
+	}
+	next := 0
+	for _, i := range unmatched {
+		for next < len(claimed) && claimed[next] {
+			next++
+		}
+		plan.indexes[i] = next
+		next++
+	}
+	return plan
+}
+
+// positionalDecodePlan returns a plan setting the outputs to the fields in order.
+func positionalDecodePlan(typ reflect.Type) *decodePlan {
+	plan := &decodePlan{tags: parseFieldTags(typ), indexes: make([]int, typ.NumField())}
+	plan.decoded = decodedFields(plan.tags)
+	position := 0
+	for i := range plan.indexes {
+		if !plan.tags[i].decoded() {
+			plan.indexes[i] = -1
+			continue
+		}
+		plan.indexes[i] = position
+		position++
+	}
+	return plan
+}
+
+// decoded reports whether the field is set from an output.
+func (tag fieldTag) decoded() bool {
+	return !tag.success && !tag.target && !tag.skip
+}
+
+// decodedFields returns the number of fields decoded from the outputs.
+func decodedFields(tags []fieldTag) int {
+	n := 0
+	for _, tag := range tags {
+		if tag.decoded() {
+			n++
+		}
+	}
+	return n
+}
+
+// setCallFields sets the success and target fields of the outputs struct
+// of the call, if any.
+func setCallFields(call *Call, success bool) {
+	t := reflect.Indirect(reflect.ValueOf(call.Outputs))
+	if t.Kind() != reflect.Struct || !t.CanSet() {
+		return
+	}
+	for i, tag := range call.Contract.fieldTags(t.Type()) {
+		field := t.Field(i)
+		switch {
+		case tag.success && field.Kind() == reflect.Bool:
+			field.SetBool(success)
+		case tag.target && field.Type() == addressType:
+			field.Set(reflect.ValueOf(call.Contract.address))
+		}
+	}
+}
+
+func parseFieldTags(typ reflect.Type) []fieldTag {
+	tags := make([]fieldTag, typ.NumField())
+	for i := range tags {
+		tags[i] = parseFieldTag(typ.Field(i))
+	}
+	return tags
+}
+
+// values returns the values to decode from the unpacked outputs.
+func (plan *decodePlan) values(out []any) ([]any, error) {
+	if !plan.flatten {
+		return out, nil
+	}
+	tuple := reflect.Indirect(reflect.ValueOf(out[0]))
+	if tuple.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tuple output is %T, not a struct", out[0])
+	}
+	components := make([]any, tuple.NumField())
+	for i := range components {
+		components[i] = tuple.Field(i).Interface()
+	}
+	return components, nil
+}
+
+// set sets the values to the fields of the struct value.
+func (plan *decodePlan) set(t reflect.Value, values []any) error {
+	for i, index := range plan.indexes {
+		if !plan.tags[i].decoded() {
+			continue
+		}
+		if index < 0 || index >= len(values) {
+			return fmt.Errorf("field '%s': no output at index %d, got %d values", t.Type().Field(i).Name, index, len(values))
+		}
+		if err := setField(t.Field(i), values[index], plan.tags[i]); err != nil {
+			return fmt.Errorf("field '%s': %v", t.Type().Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+// outputIndex returns the index of the unclaimed output named like the field,
+// or -1 when no output name matches.
+func outputIndex(fieldName string, names []string, claimed []bool) int {
+	for i, name := range names {
+		if name != "" && !claimed[i] && strings.EqualFold(name, fieldName) {
+			return i
+		}
+	}
+	return -1
+}
+
+// setField converts an unpacked output value and sets it to the field.
+func setField(field reflect.Value, value any, tag fieldTag) error {
+	if mapper, ok := lookupTypeMapper(field.Type()); ok {
+		mapped, err := mapper(value)
+		if err != nil {
+			return err
+		}
+		v := reflect.ValueOf(mapped)
+		if !v.IsValid() || !v.Type().AssignableTo(field.Type()) {
+			return fmt.Errorf("type mapper for %s returned %T", field.Type(), mapped)
+		}
+		field.Set(v)
+		return nil
+	}
+	if tag.text && field.CanAddr() {
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(textOf(value)))
+		}
+	}
+	if tag.boolish && field.Kind() == reflect.Bool {
+		n, ok := toBigInt(value)
+		if !ok {
+			return fmt.Errorf("cannot convert %T to bool", value)
+		}
+		if !n.IsInt64() || n.Int64() > 1 || n.Sign() < 0 {
+			return fmt.Errorf("cannot convert %s to bool, want 0 or 1", n)
+		}
+		field.SetBool(n.Sign() == 1)
+		return nil
+	}
+	if field.Kind() == reflect.Pointer && value != nil && reflect.TypeOf(value) != field.Type() {
+		elem := reflect.New(field.Type().Elem())
+		if err := setField(elem.Elem(), value, tag); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 {
+		if b, ok := bytesOf(value); ok {
+			field.Set(reflect.ValueOf(bytes.Clone(b)).Convert(field.Type()))
+			return nil
+		}
+	}
+	if field.Type() == jsonNumberType {
+		if n, ok := toBigInt(value); ok {
+			field.SetString(n.String())
+			return nil
+		}
+	}
+	if field.Kind() == reflect.String {
+		if b, ok := bytesOf(value); ok {
+			if tag.str {
+				field.SetString(bytesToString(b))
+			} else {
+				field.SetString(hexutil.Encode(b))
+			}
+			return nil
+		}
+	}
+	if field.Type() == timeType {
+		return setTime(field, value, tag)
+	}
+	if field.Kind() == reflect.Array {
+		if field.Type().Elem().Kind() == reflect.Uint8 {
+			if b, ok := bytesOf(value); ok {
+				return setByteArray(field, b, tag)
+			}
+		}
+		if src := reflect.ValueOf(value); src.Kind() == reflect.Slice {
+			return setArrayFromSlice(field, src, tag)
+		}
+	}
+	if field.Kind() == reflect.Slice {
+		if src := reflect.ValueOf(value); src.Kind() == reflect.Slice && src.Type().Elem() != field.Type().Elem() {
+			return setSliceFromSlice(field, src, tag)
+		}
+	}
+	if src := reflect.ValueOf(value); src.Kind() == reflect.Pointer && !src.IsNil() && src.Type().Elem() == field.Type() {
+		field.Set(src.Elem())
+		return nil
+	}
+	converted, err := convertValue(value, field.Type())
+	if err != nil {
+		return err
+	}
+	field.Set(converted)
+	return nil
+}
+
+// setArrayFromSlice copies a dynamic array output into a fixed-size array field.
+// Length mismatches are errors unless allowed by the field tag.
+func setArrayFromSlice(field, src reflect.Value, tag fieldTag) error {
+	n, size := src.Len(), field.Len()
+	if err := checkArrayLength(n, size, tag); err != nil {
+		return err
+	}
+
+	arr := reflect.New(field.Type()).Elem()
+	for i := 0; i < min(n, size); i++ {
+		elem, err := convertValue(src.Index(i).Interface(), field.Type().Elem())
+		if err != nil {
+			return fmt.Errorf("element [%d]: %v", i, err)
+		}
+		arr.Index(i).Set(elem)
+	}
+	field.Set(arr)
+	return nil
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	addressType = reflect.TypeOf(common.Address{})
+)
+
+// setTime sets a time.Time field from an integer output of Unix seconds,
+// or from a string output parsed with the layout of the tag.
+func setTime(field reflect.Value, value any, tag fieldTag) error {
+	if n, ok := toBigInt(value); ok {
+		if !n.IsInt64() {
+			return fmt.Errorf("timestamp %s overflows int64", n)
+		}
+		field.Set(reflect.ValueOf(time.Unix(n.Int64(), 0).UTC()))
+		return nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("cannot convert %T to time.Time", value)
+	}
+	layout := tag.timeLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return err
+	}
+	field.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// setSliceFromSlice sets the slice field element by element, so that elements
+// differing in pointer-ness (e.g. *big.Int and big.Int) are normalized.
+func setSliceFromSlice(field, src reflect.Value, tag fieldTag) error {
+	if src.IsNil() {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	slice := reflect.MakeSlice(field.Type(), src.Len(), src.Len())
+	for i := 0; i < src.Len(); i++ {
+		if err := setField(slice.Index(i), src.Index(i).Interface(), tag); err != nil {
+			return fmt.Errorf("element [%d]: %v", i, err)
+		}
+	}
+	field.Set(slice)
+	return nil
+}
+
+// setByteArray copies a bytes or bytesN output into a fixed-size byte array field
+// of any length. Length mismatches are errors unless allowed by the field tag.
+func setByteArray(field reflect.Value, b []byte, tag fieldTag) error {
+	if err := checkArrayLength(len(b), field.Len(), tag); err != nil {
+		return err
+	}
+	arr := reflect.New(field.Type()).Elem()
+	reflect.Copy(arr, reflect.ValueOf(b))
+	field.Set(arr)
+	return nil
+}
+
+func checkArrayLength(n, size int, tag fieldTag) error {
+	if n < size && !tag.pad {
+		return fmt.Errorf("got %d elements for array of length %d, use `abi:\"pad\"` to allow padding", n, size)
+	}
+	if n > size && !tag.truncate {
+		return fmt.Errorf("got %d elements for array of length %d, use `abi:\"truncate\"` to allow truncation", n, size)
+	}
+	return nil
+}
+
+var jsonNumberType = reflect.TypeOf(json.Number(""))
+
+// toBigInt converts an unpacked integer output value into a big.Int.
+func toBigInt(value any) (*big.Int, bool) {
+	if n, ok := value.(*big.Int); ok {
+		return n, n != nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(v.Uint()), true
+	}
+	return nil, false
+}
+
+// textOf returns the text representation of an unpacked output value.
+// Bytes are hex encoded with a 0x prefix.
+func textOf(value any) string {
+	if v, ok := value.(fmt.Stringer); ok {
+		return v.String()
+	}
+	if b, ok := bytesOf(value); ok {
+		return hexutil.Encode(b)
+	}
+	return fmt.Sprint(value)
+}
+
+// bytesOf returns the bytes of a bytes or bytesN output value.
+func bytesOf(value any) ([]byte, bool) {
+	if b, ok := value.([]byte); ok {
+		return b, true
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Array && v.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		return b, true
+	}
+	return nil, false
+}
+
+// convertValue converts an unpacked output value into the given type.
+// Big integers are converted into Go integers when they fit. abi.ConvertType
+// panics on incompatible types, so the panic is recovered and returned as
+// an error.
+func convertValue(value any, typ reflect.Type) (converted reflect.Value, err error) {
+	if n, ok := value.(*big.Int); ok && n != nil {
+		switch typ.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v := reflect.New(typ).Elem()
+			if !n.IsInt64() || v.OverflowInt(n.Int64()) {
+				return reflect.Value{}, fmt.Errorf("%s overflows %s", n, typ)
+			}
+			v.SetInt(n.Int64())
+			return v, nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v := reflect.New(typ).Elem()
+			if !n.IsUint64() || v.OverflowUint(n.Uint64()) {
+				return reflect.Value{}, fmt.Errorf("%s overflows %s", n, typ)
+			}
+			v.SetUint(n.Uint64())
+			return v, nil
+		}
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot convert %T to %s: %v", value, typ, r)
+		}
+	}()
+	return reflect.ValueOf(abi.ConvertType(value, reflect.New(typ).Interface())).Elem(), nil
+}
+
+// ZipToMap zips two array fields of the decoded outputs struct into the map
+// pointed by out, e.g. a *map[*big.Int]*big.Int for (uint256[] ids, uint256[] values).
+// Both fields must have the same length.
+func (call *Call) ZipToMap(idsField, valuesField string, out any) error {
+	t := reflect.ValueOf(call.Outputs)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return errors.New("outputs type is not a struct")
+	}
+	keys := t.FieldByName(idsField)
+	if !keys.IsValid() {
+		return fmt.Errorf("outputs have no field '%s'", idsField)
+	}
+	values := t.FieldByName(valuesField)
+	if !values.IsValid() {
+		return fmt.Errorf("outputs have no field '%s'", valuesField)
+	}
+	return zipInto(out, keys, values)
+}
+
+// ZipArrays zips two array outputs of the call, at the given output indexes,
+// into the map pointed by out, converting keys and values to the map types
+// (e.g. a *map[common.Address]*big.Int for (address[], uint256[]) outputs).
+// The outputs are decoded from the raw ReturnData of the call.
+func (call *Call) ZipArrays(out any, keyIndex, valueIndex int) error {
+	if call.Failed {
+		return fmt.Errorf("call '%s' failed", call.Method)
+	}
+	values, err := call.unpackOutputs(call.Method, call.ReturnData)
+	if err != nil {
+		return fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
+	}
+	for _, index := range []int{keyIndex, valueIndex} {
+		if index < 0 || index >= len(values) {
+			return fmt.Errorf("output index %d out of range, '%s' returns %d values", index, call.Method, len(values))
+		}
+	}
+	return zipInto(out, reflect.ValueOf(values[keyIndex]), reflect.ValueOf(values[valueIndex]))
+}
+
+// zipInto sets the pairs of the keys and values arrays to the map pointed by out.
+func zipInto(out any, keys, values reflect.Value) error {
+	m := reflect.ValueOf(out)
+	if m.Kind() != reflect.Pointer || m.Elem().Kind() != reflect.Map {
+		return fmt.Errorf("out must be a pointer to a map, got %T", out)
+	}
+	m = m.Elem()
+	if keys.Kind() != reflect.Slice && keys.Kind() != reflect.Array {
+		return fmt.Errorf("keys are %s, not an array", keys.Type())
+	}
+	if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+		return fmt.Errorf("values are %s, not an array", values.Type())
+	}
+	if keys.Len() != values.Len() {
+		return fmt.Errorf("got %d keys but %d values", keys.Len(), values.Len())
+	}
+
+	if m.IsNil() {
+		m.Set(reflect.MakeMapWithSize(m.Type(), keys.Len()))
+	}
+	for i := 0; i < keys.Len(); i++ {
+		key, err := convertValue(keys.Index(i).Interface(), m.Type().Key())
+		if err != nil {
+			return fmt.Errorf("key [%d]: %v", i, err)
+		}
+		value, err := convertValue(values.Index(i).Interface(), m.Type().Elem())
+		if err != nil {
+			return fmt.Errorf("value [%d]: %v", i, err)
+		}
+		m.SetMapIndex(key, value)
+	}
+	return nil
+}
@@ -0,0 +1,792 @@
+package multicall
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const amountsABI = `[{"type":"function","name":"amounts","stateMutability":"view","inputs":[],"outputs":[{"name":"amounts","type":"uint256[]"}]}]`
+
+type reservesOutput struct {
+	Reserve0           *big.Int
+	Reserve1           *big.Int
+	BlockTimestampLast uint32
+}
+
+func TestUnpackCachedPlan(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	data := pack(t, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+
+	// the reference decoding of go-ethereum
+	var want reservesOutput
+	if err := c.abi.UnpackIntoInterface(&want, "getReserves", data); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		var got reservesOutput
+		if err := c.NewCall(&got, "getReserves").Unpack(data); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("decode %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// an uncached plan decodes the same
+	var got reservesOutput
+	call := c.NewCall(&got, "getReserves").WithOutputABI(c.abi.Methods["getReserves"].Outputs)
+	if err := call.Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("uncached: expected %+v, got %+v", want, got)
+	}
+}
+
+func TestDecodePlanPerContract(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	typ := reflect.TypeOf(reservesOutput{})
+	if c.methodDecodePlan("getReserves", typ) != c.methodDecodePlan("getReserves", typ) {
+		t.Fatal("expected the plan to be cached")
+	}
+	other := mustContract(t, testABI, tokenAddress, WithOutputNameMatching())
+	if c.methodDecodePlan("getReserves", typ) == other.methodDecodePlan("getReserves", typ) {
+		t.Fatal("expected a plan per contract")
+	}
+}
+
+func BenchmarkUnpack(b *testing.B) {
+	c := mustContract(b, testABI, tokenAddress)
+	data := pack(b, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var out reservesOutput
+		if err := c.NewCall(&out, "getReserves").Unpack(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkUnpackUncached computes the decode plan at every decode, as before
+// plans were cached.
+func BenchmarkUnpackUncached(b *testing.B) {
+	c := mustContract(b, testABI, tokenAddress)
+	data := pack(b, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+	outputs := c.abi.Methods["getReserves"].Outputs
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var out reservesOutput
+		if err := c.NewCall(&out, "getReserves").WithOutputABI(outputs).Unpack(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestUnpackPointerSlices(t *testing.T) {
+	c := mustContract(t, amountsABI, tokenAddress)
+	data := pack(t, c, "amounts", []*big.Int{big.NewInt(1), big.NewInt(2)})
+
+	var pointers struct {
+		Amounts []*big.Int
+	}
+	if err := c.NewCall(&pointers, "amounts").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(pointers.Amounts) != 2 || pointers.Amounts[0].Int64() != 1 || pointers.Amounts[1].Int64() != 2 {
+		t.Fatalf("unexpected amounts %v", pointers.Amounts)
+	}
+
+	var values struct {
+		Amounts []big.Int
+	}
+	if err := c.NewCall(&values, "amounts").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(values.Amounts) != 2 || values.Amounts[0].Int64() != 1 || values.Amounts[1].Int64() != 2 {
+		t.Fatalf("unexpected amounts %v", values.Amounts)
+	}
+}
+
+func TestUnpackFixedArray(t *testing.T) {
+	c := mustContract(t, amountsABI, tokenAddress)
+	amounts := func(n int) []byte {
+		values := make([]*big.Int, n)
+		for i := range values {
+			values[i] = big.NewInt(int64(i + 1))
+		}
+		return pack(t, c, "amounts", values)
+	}
+
+	var exact struct {
+		Amounts [3]*big.Int
+	}
+	if err := c.NewCall(&exact, "amounts").Unpack(amounts(3)); err != nil {
+		t.Fatal(err)
+	}
+	if exact.Amounts[2].Int64() != 3 {
+		t.Fatalf("unexpected amounts %v", exact.Amounts)
+	}
+
+	var strict struct {
+		Amounts [3]*big.Int
+	}
+	if err := c.NewCall(&strict, "amounts").Unpack(amounts(2)); err == nil {
+		t.Fatal("expected error for under-length array")
+	}
+	if err := c.NewCall(&strict, "amounts").Unpack(amounts(4)); err == nil {
+		t.Fatal("expected error for over-length array")
+	}
+
+	var padded struct {
+		Amounts [3]*big.Int `abi:"pad"`
+	}
+	if err := c.NewCall(&padded, "amounts").Unpack(amounts(2)); err != nil {
+		t.Fatal(err)
+	}
+	if padded.Amounts[1].Int64() != 2 || padded.Amounts[2] != nil {
+		t.Fatalf("unexpected padded amounts %v", padded.Amounts)
+	}
+
+	var truncated struct {
+		Amounts [3]*big.Int `abi:"truncate"`
+	}
+	if err := c.NewCall(&truncated, "amounts").Unpack(amounts(4)); err != nil {
+		t.Fatal(err)
+	}
+	if truncated.Amounts[2].Int64() != 3 {
+		t.Fatalf("unexpected truncated amounts %v", truncated.Amounts)
+	}
+}
+
+type tokenAmount struct {
+	*big.Int
+}
+
+func TestUnpackTypeMapper(t *testing.T) {
+	RegisterTypeMapper(reflect.TypeOf(tokenAmount{}), func(v any) (any, error) {
+		n, ok := v.(*big.Int)
+		if !ok {
+			return nil, fmt.Errorf("unexpected %T", v)
+		}
+		return tokenAmount{n}, nil
+	})
+
+	c := mustContract(t, testABI, tokenAddress)
+	var out struct {
+		Balance tokenAmount
+	}
+	if err := c.NewCall(&out, "balanceOf", ownerAddress).Unpack(pack(t, c, "balanceOf", big.NewInt(42))); err != nil {
+		t.Fatal(err)
+	}
+	if out.Balance.Int == nil || out.Balance.Int64() != 42 {
+		t.Fatalf("unexpected balance %v", out.Balance)
+	}
+
+	var reserves struct {
+		Reserve0 tokenAmount
+		Reserve1 tokenAmount
+		Last     tokenAmount
+	}
+	data := pack(t, c, "getReserves", big.NewInt(1), big.NewInt(2), uint32(3))
+	if err := c.NewCall(&reserves, "getReserves").Unpack(data); err == nil {
+		t.Fatal("expected the mapper error for a uint32 output")
+	}
+}
+
+func TestUnpackOutputNameMatching(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress, WithOutputNameMatching())
+	data := pack(t, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+
+	var out struct {
+		BLOCKTIMESTAMPLAST uint32
+		Reserve1           *big.Int
+		RESERVE0           *big.Int
+	}
+	if err := c.NewCall(&out, "getReserves").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.RESERVE0.Int64() != 100 || out.Reserve1.Int64() != 200 || out.BLOCKTIMESTAMPLAST != 300 {
+		t.Fatalf("unexpected outputs %+v", out)
+	}
+
+	// fields without a matching name fall back to their position
+	var fallback struct {
+		First  *big.Int
+		Second *big.Int
+		Last   uint32
+	}
+	if err := c.NewCall(&fallback, "getReserves").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if fallback.First.Int64() != 100 || fallback.Second.Int64() != 200 || fallback.Last != 300 {
+		t.Fatalf("unexpected outputs %+v", fallback)
+	}
+
+	// fields without a matching name are given the outputs not matched by name
+	var mixed struct {
+		Reserve1 *big.Int
+		Other    *big.Int
+		Last     uint32
+	}
+	if err := c.NewCall(&mixed, "getReserves").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if mixed.Reserve1.Int64() != 200 || mixed.Other.Int64() != 100 || mixed.Last != 300 {
+		t.Fatalf("unexpected outputs %+v", mixed)
+	}
+
+	// an output is never decoded twice
+	var extra struct {
+		Reserve0 *big.Int
+		Reserve1 *big.Int
+		Last     uint32
+		Extra    *big.Int
+	}
+	if err := c.NewCall(&extra, "getReserves").Unpack(data); err == nil {
+		t.Fatalf("expected error for more fields than outputs, got %+v", extra)
+	}
+}
+
+func TestUnpackSkippedField(t *testing.T) {
+	data := pack(t, mustContract(t, testABI, tokenAddress), "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+
+	type reserves struct {
+		First   *big.Int
+		Ignored *big.Int `abi:"-"`
+		Second  *big.Int
+		Last    uint32
+	}
+	for _, c := range []*Contract{
+		mustContract(t, testABI, tokenAddress),
+		mustContract(t, testABI, tokenAddress, WithOutputNameMatching()),
+	} {
+		out := reserves{Ignored: big.NewInt(-1)}
+		if err := c.NewCall(&out, "getReserves").Unpack(data); err != nil {
+			t.Fatal(err)
+		}
+		if out.First.Int64() != 100 || out.Ignored.Int64() != -1 || out.Second.Int64() != 200 || out.Last != 300 {
+			t.Fatalf("unexpected outputs %+v", out)
+		}
+	}
+}
+
+const batchABI = `[
+	{"type":"function","name":"balancesOf","stateMutability":"view","inputs":[],"outputs":[{"name":"ids","type":"uint256[]"},{"name":"balances","type":"uint256[]"}]},
+	{"type":"function","name":"holders","stateMutability":"view","inputs":[],"outputs":[{"name":"accounts","type":"address[]"},{"name":"balances","type":"uint256[]"}]}
+]`
+
+func TestZipToMap(t *testing.T) {
+	c := mustContract(t, batchABI, tokenAddress)
+	data := pack(t, c, "balancesOf",
+		[]*big.Int{big.NewInt(1), big.NewInt(2)},
+		[]*big.Int{big.NewInt(10), big.NewInt(20)},
+	)
+	var out struct {
+		Ids      []*big.Int
+		Balances []*big.Int
+	}
+	call := c.NewCall(&out, "balancesOf")
+	if err := call.Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+
+	var balances map[*big.Int]*big.Int
+	if err := call.ZipToMap("Ids", "Balances", &balances); err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 {
+		t.Fatalf("expected 2 balances, got %d", len(balances))
+	}
+	for id, balance := range balances {
+		if balance.Int64() != id.Int64()*10 {
+			t.Fatalf("unexpected balance %s for id %s", balance, id)
+		}
+	}
+
+	if err := call.ZipToMap("Ids", "Amounts", &balances); err == nil {
+		t.Fatal("expected error for a missing field")
+	}
+	out.Balances = out.Balances[:1]
+	if err := call.ZipToMap("Ids", "Balances", &balances); err == nil {
+		t.Fatal("expected error for arrays of different lengths")
+	}
+}
+
+// weiText records the text it is unmarshaled from.
+type weiText struct {
+	text string
+}
+
+func (w *weiText) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		return errors.New("empty text")
+	}
+	w.text = string(b) + " wei"
+	return nil
+}
+
+func TestUnpackTextUnmarshaler(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	data := pack(t, c, "balanceOf", big.NewInt(42))
+
+	var out struct {
+		Balance weiText `abi:"text"`
+	}
+	if err := c.NewCall(&out, "balanceOf", ownerAddress).Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Balance.text != "42 wei" {
+		t.Fatalf("unexpected text %q", out.Balance.text)
+	}
+
+	var name struct {
+		Name weiText `abi:"text"`
+	}
+	if err := c.NewCall(&name, "name").Unpack(pack(t, c, "name", "")); err == nil {
+		t.Fatal("expected the UnmarshalText error")
+	}
+}
+
+func TestUnpackBoolish(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	tests := []struct {
+		value   int64
+		want    bool
+		wantErr bool
+	}{
+		{0, false, false},
+		{1, true, false},
+		{2, false, true},
+	}
+	for _, tt := range tests {
+		var out struct {
+			Enabled bool `abi:"boolish"`
+		}
+		err := c.NewCall(&out, "balanceOf", ownerAddress).Unpack(pack(t, c, "balanceOf", big.NewInt(tt.value)))
+		if tt.wantErr != (err != nil) {
+			t.Fatalf("value %d: expected error %v, got %v", tt.value, tt.wantErr, err)
+		}
+		if out.Enabled != tt.want {
+			t.Fatalf("value %d: expected %v", tt.value, tt.want)
+		}
+	}
+}
+
+func TestZipArrays(t *testing.T) {
+	c := mustContract(t, batchABI, tokenAddress)
+
+	call := c.NewCall(nil, "holders")
+	call.ReturnData = pack(t, c, "holders",
+		[]common.Address{ownerAddress, tokenAddress},
+		[]*big.Int{big.NewInt(10), big.NewInt(20)},
+	)
+	var holders map[common.Address]*big.Int
+	if err := call.ZipArrays(&holders, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(holders) != 2 || holders[ownerAddress].Int64() != 10 || holders[tokenAddress].Int64() != 20 {
+		t.Fatalf("unexpected holders %v", holders)
+	}
+
+	call = c.NewCall(nil, "balancesOf")
+	call.ReturnData = pack(t, c, "balancesOf",
+		[]*big.Int{big.NewInt(1), big.NewInt(2)},
+		[]*big.Int{big.NewInt(10), big.NewInt(20)},
+	)
+	var balances map[uint64]uint64
+	if err := call.ZipArrays(&balances, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 || balances[1] != 10 || balances[2] != 20 {
+		t.Fatalf("unexpected balances %v", balances)
+	}
+
+	if err := call.ZipArrays(&balances, 0, 2); err == nil {
+		t.Fatal("expected error for an output index out of range")
+	}
+}
+
+const bytesABI = `[
+	{"type":"function","name":"data","stateMutability":"view","inputs":[],"outputs":[{"name":"data","type":"bytes"}]},
+	{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"symbol","type":"bytes32"}]}
+]`
+
+func TestUnpackBytesToHex(t *testing.T) {
+	c := mustContract(t, bytesABI, tokenAddress)
+
+	var out struct {
+		Data string
+	}
+	if err := c.NewCall(&out, "data").Unpack(pack(t, c, "data", []byte{0xde, 0xad, 0xbe, 0xef})); err != nil {
+		t.Fatal(err)
+	}
+	if out.Data != "0xdeadbeef" {
+		t.Fatalf("unexpected data %q", out.Data)
+	}
+
+	var symbol struct {
+		Symbol string
+	}
+	if err := c.NewCall(&symbol, "symbol").Unpack(pack(t, c, "symbol", [32]byte{0x01})); err != nil {
+		t.Fatal(err)
+	}
+	if want := "0x01" + strings.Repeat("00", 31); symbol.Symbol != want {
+		t.Fatalf("unexpected symbol %q", symbol.Symbol)
+	}
+}
+
+func TestUnpackOrdered(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	data := pack(t, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+
+	var out struct {
+		Last     uint32
+		Reserve1 *big.Int
+		Reserve0 *big.Int
+	}
+	call := c.NewCall(&out, "getReserves")
+	if err := call.UnpackOrdered(data, []int{2, 1, 0}); err != nil {
+		t.Fatal(err)
+	}
+	if out.Last != 300 || out.Reserve1.Int64() != 200 || out.Reserve0.Int64() != 100 {
+		t.Fatalf("unexpected outputs %+v", out)
+	}
+
+	if err := call.UnpackOrdered(data, []int{2, 1}); err == nil {
+		t.Fatal("expected error for a short field order")
+	}
+	if err := call.UnpackOrdered(data, []int{3, 1, 0}); err == nil {
+		t.Fatal("expected error for an index out of range")
+	}
+}
+
+const poolABI = `[{"type":"function","name":"slot0","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"tuple","components":[
+	{"name":"sqrtPriceX96","type":"uint160"},
+	{"name":"tick","type":"int24"},
+	{"name":"observationIndex","type":"uint16"},
+	{"name":"feeProtocol","type":"uint8"},
+	{"name":"unlocked","type":"bool"}
+]}]}]`
+
+func TestUnpackTupleFlatten(t *testing.T) {
+	c := mustContract(t, poolABI, tokenAddress)
+	slot0 := struct {
+		SqrtPriceX96     *big.Int
+		Tick             *big.Int
+		ObservationIndex uint16
+		FeeProtocol      uint8
+		Unlocked         bool
+	}{big.NewInt(1 << 40), big.NewInt(-5), 7, 4, true}
+	data := pack(t, c, "slot0", slot0)
+
+	var out struct {
+		Price    *big.Int
+		Tick     *big.Int
+		Index    uint16
+		Fee      uint8
+		Unlocked bool
+	}
+	if err := c.NewCall(&out, "slot0").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Price.Cmp(slot0.SqrtPriceX96) != 0 || out.Tick.Int64() != -5 || out.Index != 7 || out.Fee != 4 || !out.Unlocked {
+		t.Fatalf("unexpected outputs %+v", out)
+	}
+}
+
+const positionsABI = `[{"type":"function","name":"positions","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"tuple[]","components":[
+	{"name":"owner","type":"address"},
+	{"name":"liquidity","type":"uint128"},
+	{"name":"active","type":"bool"}
+]}]}]`
+
+type position struct {
+	Owner     common.Address
+	Liquidity *big.Int
+	Active    bool
+}
+
+func TestUnpackMaps(t *testing.T) {
+	c := mustContract(t, positionsABI, tokenAddress)
+	positions := []position{
+		{ownerAddress, big.NewInt(1), true},
+		{tokenAddress, big.NewInt(2), false},
+		{common.Address{}, big.NewInt(3), true},
+	}
+	maps, err := c.NewCall(nil, "positions").UnpackMaps(pack(t, c, "positions", positions))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(maps) != 3 {
+		t.Fatalf("expected 3 maps, got %d", len(maps))
+	}
+	for i, m := range maps {
+		if len(m) != 3 || m["owner"] != positions[i].Owner ||
+			m["liquidity"].(*big.Int).Cmp(positions[i].Liquidity) != 0 || m["active"] != positions[i].Active {
+			t.Fatalf("map %d: unexpected %v", i, m)
+		}
+	}
+
+	if _, err := c.NewCall(nil, "balanceOf").UnpackMaps(nil); err == nil {
+		t.Fatal("expected error for an unknown method")
+	}
+}
+
+func TestUnpackTyped(t *testing.T) {
+	c := mustContract(t, `[{"type":"function","name":"info","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"amount","type":"uint256"},
+		{"name":"owner","type":"address"},
+		{"name":"active","type":"bool"}
+	]}]`, tokenAddress)
+	values, err := c.NewCall(nil, "info").UnpackTyped(pack(t, c, "info", big.NewInt(42), ownerAddress, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TypedValue{
+		{SolidityType: "uint256", Value: big.NewInt(42)},
+		{SolidityType: "address", Value: ownerAddress},
+		{SolidityType: "bool", Value: true},
+	}
+	if !reflect.DeepEqual(values, want) {
+		t.Fatalf("expected %v, got %v", want, values)
+	}
+}
+
+func TestUnpackJSONNumber(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	var out struct {
+		Balance json.Number
+	}
+	if err := c.NewCall(&out, "balanceOf", ownerAddress).Unpack(pack(t, c, "balanceOf", maxUint256)); err != nil {
+		t.Fatal(err)
+	}
+	if out.Balance.String() != maxUint256.String() {
+		t.Fatalf("unexpected balance %s", out.Balance)
+	}
+}
+
+func TestUnpackFixedBytes(t *testing.T) {
+	for n := 1; n <= 32; n++ {
+		t.Run(fmt.Sprintf("bytes%d", n), func(t *testing.T) {
+			c := mustContract(t, fmt.Sprintf(`[{"type":"function","name":"value","stateMutability":"view","inputs":[],"outputs":[{"name":"value","type":"bytes%d"}]}]`, n), tokenAddress)
+			arrayType := reflect.ArrayOf(n, reflect.TypeOf(byte(0)))
+			value := reflect.New(arrayType).Elem()
+			for i := 0; i < n; i++ {
+				value.Index(i).SetUint(uint64(i + 1))
+			}
+			data := pack(t, c, "value", value.Interface())
+
+			out := reflect.New(reflect.StructOf([]reflect.StructField{{Name: "Value", Type: arrayType}}))
+			if err := c.NewCall(out.Interface(), "value").Unpack(data); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.Elem().Field(0).Interface(); got != value.Interface() {
+				t.Fatalf("expected %x, got %x", value.Interface(), got)
+			}
+		})
+	}
+}
+
+func TestUnpackFixedBytesLength(t *testing.T) {
+	c := mustContract(t, `[{"type":"function","name":"value","stateMutability":"view","inputs":[],"outputs":[{"name":"value","type":"bytes4"}]}]`, tokenAddress)
+	data := pack(t, c, "value", [4]byte{1, 2, 3, 4})
+
+	var long struct {
+		Value [8]byte
+	}
+	if err := c.NewCall(&long, "value").Unpack(data); err == nil {
+		t.Fatal("expected error for a longer array")
+	}
+	var padded struct {
+		Value [8]byte `abi:"pad"`
+	}
+	if err := c.NewCall(&padded, "value").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if padded.Value != [8]byte{1, 2, 3, 4} {
+		t.Fatalf("unexpected value %x", padded.Value)
+	}
+	var truncated struct {
+		Value [2]byte `abi:"truncate"`
+	}
+	if err := c.NewCall(&truncated, "value").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if truncated.Value != [2]byte{1, 2} {
+		t.Fatalf("unexpected value %x", truncated.Value)
+	}
+}
+
+func TestUnpackStrictDecode(t *testing.T) {
+	data := func(c *Contract) []byte {
+		return pack(t, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+	}
+	type fewer struct {
+		Reserve0 *big.Int
+		Reserve1 *big.Int
+	}
+	type more struct {
+		Reserve0           *big.Int
+		Reserve1           *big.Int
+		BlockTimestampLast uint32
+		Extra              *big.Int
+	}
+
+	lenient := mustContract(t, testABI, tokenAddress)
+	if err := lenient.NewCall(new(fewer), "getReserves").Unpack(data(lenient)); err != nil {
+		t.Fatalf("expected extra outputs to be ignored, got %v", err)
+	}
+
+	strict := mustContract(t, testABI, tokenAddress, WithStrictDecode())
+	for _, outputs := range []any{new(fewer), new(more)} {
+		err := strict.NewCall(outputs, "getReserves").Unpack(data(strict))
+		if err == nil || !strings.Contains(err.Error(), "getReserves") {
+			t.Fatalf("expected a mismatch error naming the method for %T, got %v", outputs, err)
+		}
+	}
+	if err := strict.NewCall(new(reservesOutput), "getReserves").Unpack(data(strict)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnpackBytesField(t *testing.T) {
+	c := mustContract(t, `[{"type":"function","name":"payload","stateMutability":"view","inputs":[],"outputs":[{"name":"id","type":"uint256"},{"name":"data","type":"bytes"}]}]`, tokenAddress)
+	payload := []byte{0xca, 0xfe}
+	var out struct {
+		ID   *big.Int
+		Data []byte
+	}
+	if err := c.NewCall(&out, "payload").Unpack(pack(t, c, "payload", big.NewInt(7), payload)); err != nil {
+		t.Fatal(err)
+	}
+	if out.ID.Int64() != 7 || !bytes.Equal(out.Data, payload) {
+		t.Fatalf("unexpected outputs %+v", out)
+	}
+}
+
+const routerABI = `[
+	{"type":"function","name":"execute","stateMutability":"view","inputs":[],"outputs":[{"name":"success","type":"bool"},{"name":"data","type":"bytes"}]},
+	{"type":"function","name":"getReserves","stateMutability":"view","inputs":[],"outputs":[{"name":"reserve0","type":"uint112"},{"name":"reserve1","type":"uint112"},{"name":"blockTimestampLast","type":"uint32"}]}
+]`
+
+func TestUnpackNested(t *testing.T) {
+	c := mustContract(t, routerABI, tokenAddress)
+	inner := pack(t, c, "getReserves", big.NewInt(100), big.NewInt(200), uint32(300))
+
+	var out reservesOutput
+	if err := c.NewCall(&out, "execute").UnpackNested(pack(t, c, "execute", true, inner), "getReserves"); err != nil {
+		t.Fatal(err)
+	}
+	if out.Reserve0.Int64() != 100 || out.Reserve1.Int64() != 200 || out.BlockTimestampLast != 300 {
+		t.Fatalf("unexpected outputs %+v", out)
+	}
+
+	err := c.NewCall(new(reservesOutput), "execute").UnpackNested(pack(t, c, "execute", false, revertData("inner boom")), "getReserves")
+	if err == nil || !strings.Contains(err.Error(), "inner boom") {
+		t.Fatalf("expected the inner revert reason, got %v", err)
+	}
+}
+
+// reservesSum implements OutputUnmarshaler, summing the reserves.
+type reservesSum struct {
+	Total *big.Int
+}
+
+func (r *reservesSum) UnmarshalABI(out []any) error {
+	if len(out) != 3 {
+		return fmt.Errorf("got %d outputs", len(out))
+	}
+	r.Total = new(big.Int).Add(out[0].(*big.Int), out[1].(*big.Int))
+	return nil
+}
+
+func TestUnpackOutputUnmarshaler(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+	var sum reservesSum
+	if _, err := caller.Call(nil, c.NewCall(&sum, "getReserves")); err != nil {
+		t.Fatal(err)
+	}
+	if sum.Total.Int64() != 300 {
+		t.Fatalf("unexpected total %s", sum.Total)
+	}
+}
+
+func TestUnpackOutputABIOverride(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	// balanceOf returning extra trailing data the ABI does not declare
+	outputs := abi.Arguments{
+		{Name: "balance", Type: mustType("uint256")},
+		{Name: "updatedAt", Type: mustType("uint64")},
+	}
+	data, err := outputs.Pack(big.NewInt(42), uint64(1700000000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Balance   *big.Int
+		UpdatedAt uint64
+	}
+	if err := c.NewCall(&out, "balanceOf", ownerAddress).WithOutputABI(outputs).Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if out.Balance.Int64() != 42 || out.UpdatedAt != 1700000000 {
+		t.Fatalf("unexpected outputs %+v", out)
+	}
+
+	// the override is for this call only
+	var plain struct {
+		Balance   *big.Int
+		UpdatedAt uint64
+	}
+	if err := c.NewCall(&plain, "balanceOf", ownerAddress).Unpack(data); err == nil {
+		t.Fatal("expected the contract ABI to declare a single output")
+	}
+
+	if _, err := c.NewCall(&out, "balanceOf", ownerAddress).WithOutputABI(nil).Pack(); err == nil {
+		t.Fatal("expected error for an empty override")
+	}
+}
+
+func TestUnpackTimeLayout(t *testing.T) {
+	c := mustContract(t, `[{"type":"function","name":"dates","stateMutability":"view","inputs":[],"outputs":[
+		{"name":"day","type":"string"},
+		{"name":"stamp","type":"string"},
+		{"name":"unix","type":"uint256"}
+	]}]`, tokenAddress)
+	data := pack(t, c, "dates", "2024-03-15", "2024-03-15T10:30:00Z", big.NewInt(1710498600))
+
+	var out struct {
+		Day   time.Time `abi:"time:2006-01-02"`
+		Stamp time.Time
+		Unix  time.Time
+	}
+	if err := c.NewCall(&out, "dates").Unpack(data); err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC); !out.Day.Equal(want) {
+		t.Fatalf("expected day %s, got %s", want, out.Day)
+	}
+	if want := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC); !out.Stamp.Equal(want) || !out.Unix.Equal(want) {
+		t.Fatalf("expected %s, got %s and %s", want, out.Stamp, out.Unix)
+	}
+
+	var invalid struct {
+		Day time.Time `abi:"time:02/01/2006"`
+	}
+	if err := c.NewCall(&invalid, "dates").Unpack(data); err == nil {
+		t.Fatal("expected error for a mismatching layout")
+	}
+}
@@ -0,0 +1,73 @@
+package multicall
+
+import (
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pinealctx/multicall/contract"
+)
+
+// DedupStats reports how many calls deduplication saved in a multicall.
+type DedupStats struct {
+	// Original is the number of calls given.
+	Original int
+	// Unique is the number of calls actually sent.
+	Unique int
+	// Saved is the number of duplicate calls not sent.
+	Saved int
+}
+
+// DedupObserver is an optional interface of an Observer receiving
+// the deduplication statistics of each multicall made with WithDeduplicate.
+type DedupObserver interface {
+	OnBatchDeduplicated(stats DedupStats)
+}
+
+// CallDedup is like Call with aggregate3, deduplicating the calls as with
+// WithDeduplicate whether or not the caller has it set, and returns how many
+// calls deduplication saved.
+func (caller *Caller) CallDedup(opts *bind.CallOpts, calls ...*Call) ([]*Call, DedupStats, error) {
+	return caller.aggregate3(caller.contract, opts, calls, true)
+}
+
+// deduplicate removes identical calls (same target, calldata and failure mode).
+// indexes maps each original call to its unique call.
+func deduplicate(multiCalls []contract.Multicall3Call3) (unique []contract.Multicall3Call3, indexes []int, stats DedupStats) {
+	type key struct {
+		target       [20]byte
+		allowFailure bool
+		callData     string
+	}
+	seen := make(map[key]int, len(multiCalls))
+	indexes = make([]int, len(multiCalls))
+	for i, multiCall := range multiCalls {
+		k := key{target: multiCall.Target, allowFailure: multiCall.AllowFailure, callData: string(multiCall.CallData)}
+		index, ok := seen[k]
+		if !ok {
+			index = len(unique)
+			seen[k] = index
+			unique = append(unique, multiCall)
+		}
+		indexes[i] = index
+	}
+	stats = DedupStats{
+		Original: len(multiCalls),
+		Unique:   len(unique),
+		Saved:    len(multiCalls) - len(unique),
+	}
+	return unique, indexes, stats
+}
+
+// duplicateResults expands the results of the unique calls back to all calls.
+func duplicateResults(results []contract.Multicall3Result, indexes []int) []contract.Multicall3Result {
+	all := make([]contract.Multicall3Result, len(indexes))
+	for i, index := range indexes {
+		all[i] = results[index]
+	}
+	return all
+}
+
+func (caller *Caller) observeDeduplicated(stats DedupStats) {
+	caller.logf("multicall: deduplicated %d calls into %d", stats.Original, stats.Unique)
+	if observer, ok := caller.observer.(DedupObserver); ok {
+		observer.OnBatchDeduplicated(stats)
+	}
+}
@@ -0,0 +1,60 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+)
+
+type dedupRecorder struct {
+	stats []DedupStats
+}
+
+func (r *dedupRecorder) OnBatchEncoded(calldataBytes int) {}
+
+func (r *dedupRecorder) OnBatchDecoded(returnBytes int) {}
+
+func (r *dedupRecorder) OnBatchDeduplicated(stats DedupStats) {
+	r.stats = append(r.stats, stats)
+}
+
+func TestCallDedup(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+	calls := []*Call{
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress),
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress),
+		c.NewCall(new(big.Int), "balanceOf", tokenAddress),
+	}
+	_, stats, err := caller.CallDedup(nil, calls...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (DedupStats{Original: 3, Unique: 2, Saved: 1}); stats != want {
+		t.Fatalf("expected %+v, got %+v", want, stats)
+	}
+	if sizes := chain.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Fatalf("expected a single multicall of 2 calls, got %v", sizes)
+	}
+	for i, want := range []int64{0xbb, 0xbb, 0xaa} {
+		if got := calls[i].Outputs.(*big.Int).Int64(); got != want {
+			t.Fatalf("call %d: expected %d, got %d", i, want, got)
+		}
+	}
+}
+
+func TestDedupObserver(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	recorder := &dedupRecorder{}
+	caller := newTestCaller(t, newFakeChain(t), WithDeduplicate(), WithObserver(recorder))
+	_, err := caller.Call(nil,
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress),
+		c.NewCall(new(big.Int), "balanceOf", ownerAddress),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.stats) != 1 || recorder.stats[0].Saved != 1 {
+		t.Fatalf("unexpected stats %+v", recorder.stats)
+	}
+}
@@ -0,0 +1,60 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pinealctx/multicall/contract"
+)
+
+// Diagnosis is the outcome of re-running a failed call alone.
+type Diagnosis struct {
+	Call *Call
+	// Reason is the decoded revert reason, or the hex revert data when it cannot be decoded.
+	Reason string
+	// Err is the error of the standalone run, nil if the call succeeded this time.
+	Err error
+}
+
+// DiagnoseFailed re-runs each failed call alone in its own multicall to find out
+// why it failed. Calls that succeed alone are updated in place.
+func (caller *Caller) DiagnoseFailed(opts *bind.CallOpts, calls []*Call) []Diagnosis {
+	opts = caller.callOpts(opts)
+	var diagnoses []Diagnosis
+	for _, call := range FailedCalls(calls) {
+		diagnoses = append(diagnoses, caller.diagnose(opts, call))
+	}
+	return diagnoses
+}
+
+func (caller *Caller) diagnose(opts *bind.CallOpts, call *Call) Diagnosis {
+	diagnosis := Diagnosis{Call: call}
+	b, err := call.Pack()
+	if err != nil {
+		diagnosis.Err = fmt.Errorf("failed to pack call inputs: %v", err)
+		return diagnosis
+	}
+
+	var results []contract.Multicall3Result
+	err = caller.retry(opts.Context, func() (err error) {
+		results, err = caller.contract.Aggregate3(opts, []contract.Multicall3Call3{{
+			Target:       call.Contract.address,
+			AllowFailure: true,
+			CallData:     b,
+		}})
+		return err
+	})
+	if err != nil {
+		diagnosis.Err = fmt.Errorf("multicall failed: %v", err)
+		return diagnosis
+	}
+
+	if err := unpackResults([]*Call{call}, results, caller.onResult); err != nil {
+		diagnosis.Err = call.Err
+	}
+	if call.Failed {
+		diagnosis.Reason = call.RevertReason
+		diagnosis.Err = fmt.Errorf("call '%s' reverted: %s", call.label(), diagnosis.Reason)
+	}
+	return diagnosis
+}
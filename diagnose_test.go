@@ -0,0 +1,38 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestDiagnoseFailed(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+
+	type info struct {
+		Balance *big.Int
+		Success bool `abi:"success"`
+	}
+	ok := c.NewCall(new(info), "balanceOf", ownerAddress).AllowFailure()
+	failed := c.NewCall(new(info), "fail").AllowFailure()
+	if _, err := caller.Call(nil, ok, failed); err != nil {
+		t.Fatal(err)
+	}
+	if !failed.Failed || !errors.Is(failed.Err, ErrCallFailed) {
+		t.Fatalf("expected failed call, got %v", failed.Err)
+	}
+
+	// the call succeeds when run alone
+	ok.Failed, ok.Err = true, ErrCallFailed
+	diagnoses := caller.DiagnoseFailed(nil, []*Call{ok, failed})
+	if len(diagnoses) != 2 {
+		t.Fatalf("expected 2 diagnoses, got %d", len(diagnoses))
+	}
+	if diagnoses[0].Err != nil || ok.Failed || ok.Err != nil || !ok.Outputs.(*info).Success {
+		t.Fatalf("expected call to succeed alone, got %v", diagnoses[0].Err)
+	}
+	if diagnoses[1].Reason != "boom" || !errors.Is(failed.Err, ErrCallFailed) || failed.Outputs.(*info).Success {
+		t.Fatalf("unexpected diagnosis %+v", diagnoses[1])
+	}
+}
@@ -0,0 +1,163 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// endpointMaxFailures is the number of consecutive failures taking
+	// an endpoint out of rotation.
+	endpointMaxFailures = 3
+	// endpointCooldown is how long an endpoint stays out of rotation.
+	endpointCooldown = 30 * time.Second
+)
+
+type endpoint struct {
+	url       string
+	client    bind.ContractCaller
+	failures  int
+	downUntil time.Time
+}
+
+// endpointsCaller is a bind.ContractCaller spreading eth_calls over several
+// endpoints round-robin, failing over to the next endpoint on node errors.
+type endpointsCaller struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	next      int
+}
+
+// dialEndpoints dials every RPC URL.
+func dialEndpoints(ctx context.Context, urls []string, capture func(reqBody, respBody []byte)) (*endpointsCaller, error) {
+	c := &endpointsCaller{}
+	for _, url := range urls {
+		client, err := dial(ctx, url, capture)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to dial %s: %v", url, err)
+		}
+		c.endpoints = append(c.endpoints, &endpoint{url: url, client: client})
+	}
+	return c, nil
+}
+
+// rotation returns the endpoints in the order to try them: the endpoints in
+// rotation round-robin, then those out of rotation as a last resort.
+func (c *endpointsCaller) rotation() []*endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	var up, down []*endpoint
+	for i := range c.endpoints {
+		e := c.endpoints[(c.next+i)%len(c.endpoints)]
+		if now.Before(e.downUntil) {
+			down = append(down, e)
+		} else {
+			up = append(up, e)
+		}
+	}
+	c.next = (c.next + 1) % len(c.endpoints)
+	return append(up, down...)
+}
+
+// report records the outcome of an eth_call sent to the endpoint.
+func (c *endpointsCaller) report(e *endpoint, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !isNodeError(err) {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures >= endpointMaxFailures {
+		e.failures = 0
+		e.downUntil = time.Now().Add(endpointCooldown)
+	}
+}
+
+// do runs fn with the endpoints in turn until one does not fail with
+// a node error.
+func (c *endpointsCaller) do(fn func(client bind.ContractCaller) ([]byte, error)) ([]byte, error) {
+	var err error
+	for _, e := range c.rotation() {
+		var b []byte
+		b, err = fn(e.client)
+		c.report(e, err)
+		if !isNodeError(err) {
+			return b, err
+		}
+		err = fmt.Errorf("endpoint %s: %w", e.url, err)
+	}
+	return nil, err
+}
+
+func (c *endpointsCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.do(func(client bind.ContractCaller) ([]byte, error) {
+		return client.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (c *endpointsCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.do(func(client bind.ContractCaller) ([]byte, error) {
+		return client.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (c *endpointsCaller) PendingCodeAt(ctx context.Context, contract common.Address) ([]byte, error) {
+	return c.do(func(client bind.ContractCaller) ([]byte, error) {
+		pending, ok := client.(bind.PendingContractCaller)
+		if !ok {
+			return nil, bind.ErrNoPendingState
+		}
+		return pending.PendingCodeAt(ctx, contract)
+	})
+}
+
+func (c *endpointsCaller) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	return c.do(func(client bind.ContractCaller) ([]byte, error) {
+		pending, ok := client.(bind.PendingContractCaller)
+		if !ok {
+			return nil, bind.ErrNoPendingState
+		}
+		return pending.PendingCallContract(ctx, call)
+	})
+}
+
+func (c *endpointsCaller) CodeAtHash(ctx context.Context, contract common.Address, blockHash common.Hash) ([]byte, error) {
+	return c.do(func(client bind.ContractCaller) ([]byte, error) {
+		byHash, ok := client.(bind.BlockHashContractCaller)
+		if !ok {
+			return nil, bind.ErrNoBlockHashState
+		}
+		return byHash.CodeAtHash(ctx, contract, blockHash)
+	})
+}
+
+func (c *endpointsCaller) CallContractAtHash(ctx context.Context, call ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
+	return c.do(func(client bind.ContractCaller) ([]byte, error) {
+		byHash, ok := client.(bind.BlockHashContractCaller)
+		if !ok {
+			return nil, bind.ErrNoBlockHashState
+		}
+		return byHash.CallContractAtHash(ctx, call, blockHash)
+	})
+}
+
+// Close closes the clients of all endpoints.
+func (c *endpointsCaller) Close() {
+	for _, e := range c.endpoints {
+		if closer, ok := e.client.(interface{ Close() }); ok {
+			closer.Close()
+		}
+	}
+}
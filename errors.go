@@ -0,0 +1,34 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// CallCollectErrors makes multicalls like Call but reports errors per call
+// instead of a single error, for best-effort scans. The returned map holds the
+// Call.Err of every call that did not succeed, keyed by call name, so calls
+// should be named. Unnamed calls are keyed by their method and index, e.g.
+// "balanceOf[3]". Calls not received because the multicall itself failed map
+// to the multicall error.
+func (caller *Caller) CallCollectErrors(opts *bind.CallOpts, calls ...*Call) ([]*Call, map[string]error) {
+	_, callErr := caller.Call(opts, calls...)
+	errs := make(map[string]error)
+	for i, call := range calls {
+		switch {
+		case call.UpdatedAt.IsZero() && callErr != nil:
+			errs[errorKey(call, i)] = callErr
+		case call.Err != nil:
+			errs[errorKey(call, i)] = call.Err
+		}
+	}
+	return calls, errs
+}
+
+func errorKey(call *Call, index int) string {
+	if call.CallName != "" {
+		return call.CallName
+	}
+	return fmt.Sprintf("%s[%d]", call.Method, index)
+}
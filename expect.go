@@ -0,0 +1,81 @@
+package multicall
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+)
+
+// Expecting sets the expected outputs of the call. After decoding, the call is
+// marked as Unexpected when its outputs differ. The expected value is either
+// the raw return data as []byte, or a value of the outputs type.
+func (call *Call) Expecting(expected any) *Call {
+	call.Expect = expected
+	return call
+}
+
+// UnexpectedCalls returns the calls whose outputs differ from their expected value.
+func UnexpectedCalls(calls []*Call) []*Call {
+	var unexpected []*Call
+	for _, call := range calls {
+		if call.Unexpected {
+			unexpected = append(unexpected, call)
+		}
+	}
+	return unexpected
+}
+
+// matchesExpectation compares the expected value with the raw return data
+// or the decoded outputs.
+func (call *Call) matchesExpectation(b []byte) bool {
+	if expected, ok := call.Expect.([]byte); ok {
+		return bytes.Equal(expected, b)
+	}
+	return valuesEqual(reflect.Indirect(reflect.ValueOf(call.Expect)), reflect.Indirect(reflect.ValueOf(call.Outputs)))
+}
+
+var bigIntType = reflect.TypeOf(big.Int{})
+
+// valuesEqual deeply compares two values, comparing big integers by value.
+func valuesEqual(a, b reflect.Value) bool {
+	if !a.IsValid() || !b.IsValid() {
+		return a.IsValid() == b.IsValid()
+	}
+	if a.Type() != b.Type() {
+		return false
+	}
+	if a.Type() == bigIntType && a.CanInterface() {
+		x, y := a.Interface().(big.Int), b.Interface().(big.Int)
+		return x.Cmp(&y) == 0
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return valuesEqual(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !valuesEqual(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !valuesEqual(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		return a.CanInterface() && reflect.DeepEqual(a.Interface(), b.Interface())
+	case reflect.Func, reflect.Chan:
+		return a.Pointer() == b.Pointer()
+	}
+	return a.Equal(b)
+}
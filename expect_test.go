@@ -0,0 +1,33 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestExpecting(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+
+	calls := []*Call{
+		c.NewCall(new(balanceOutput), "balanceOf", ownerAddress).Name("struct match").
+			Expecting(&balanceOutput{Balance: big.NewInt(0xbb)}),
+		c.NewCall(new(balanceOutput), "balanceOf", ownerAddress).Name("struct drift").
+			Expecting(&balanceOutput{Balance: big.NewInt(1)}),
+		c.NewCall(new(big.Int), "balanceOf", tokenAddress).Name("raw match").
+			Expecting(pack(t, c, "balanceOf", big.NewInt(0xaa))),
+		c.NewCall(new(big.Int), "balanceOf", tokenAddress).Name("raw drift").
+			Expecting(pack(t, c, "balanceOf", big.NewInt(1))),
+		c.NewCall(new(big.Int), "balanceOf", tokenAddress).Name("no expectation"),
+	}
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	unexpected := UnexpectedCalls(calls)
+	if len(unexpected) != 2 || unexpected[0].CallName != "struct drift" || unexpected[1].CallName != "raw drift" {
+		for _, call := range unexpected {
+			t.Log(call.CallName)
+		}
+		t.Fatalf("expected the 2 drifting calls, got %d", len(unexpected))
+	}
+}
@@ -0,0 +1,86 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// feeCaller is a bind.ContractCaller setting EIP-1559 fee fields on every
+// eth_call, for simulations depending on the base fee or gas price.
+type feeCaller struct {
+	bind.ContractCaller
+	maxFeePerGas         *big.Int
+	maxPriorityFeePerGas *big.Int
+}
+
+// withFees sets the fees given to the caller on the message, leaving the fee
+// fields not given as they are. Nodes reject a gas price along with EIP-1559
+// fees, so a message with a gas price fails.
+func (c *feeCaller) withFees(msg ethereum.CallMsg) (ethereum.CallMsg, error) {
+	if c.maxFeePerGas == nil && c.maxPriorityFeePerGas == nil {
+		return msg, nil
+	}
+	if msg.GasPrice != nil {
+		return msg, errors.New("gas price cannot be set along with the fee context")
+	}
+	if c.maxFeePerGas != nil {
+		msg.GasFeeCap = c.maxFeePerGas
+	}
+	if c.maxPriorityFeePerGas != nil {
+		msg.GasTipCap = c.maxPriorityFeePerGas
+	}
+	return msg, nil
+}
+
+func (c *feeCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	msg, err := c.withFees(msg)
+	if err != nil {
+		return nil, err
+	}
+	return c.ContractCaller.CallContract(ctx, msg, blockNumber)
+}
+
+func (c *feeCaller) PendingCodeAt(ctx context.Context, contract common.Address) ([]byte, error) {
+	pending, ok := c.ContractCaller.(bind.PendingContractCaller)
+	if !ok {
+		return nil, bind.ErrNoPendingState
+	}
+	return pending.PendingCodeAt(ctx, contract)
+}
+
+func (c *feeCaller) PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	pending, ok := c.ContractCaller.(bind.PendingContractCaller)
+	if !ok {
+		return nil, bind.ErrNoPendingState
+	}
+	msg, err := c.withFees(msg)
+	if err != nil {
+		return nil, err
+	}
+	return pending.PendingCallContract(ctx, msg)
+}
+
+func (c *feeCaller) CodeAtHash(ctx context.Context, contract common.Address, blockHash common.Hash) ([]byte, error) {
+	byHash, ok := c.ContractCaller.(bind.BlockHashContractCaller)
+	if !ok {
+		return nil, bind.ErrNoBlockHashState
+	}
+	return byHash.CodeAtHash(ctx, contract, blockHash)
+}
+
+func (c *feeCaller) CallContractAtHash(ctx context.Context, msg ethereum.CallMsg, blockHash common.Hash) ([]byte, error) {
+	byHash, ok := c.ContractCaller.(bind.BlockHashContractCaller)
+	if !ok {
+		return nil, bind.ErrNoBlockHashState
+	}
+	msg, err := c.withFees(msg)
+	if err != nil {
+		return nil, err
+	}
+	return byHash.CallContractAtHash(ctx, msg, blockHash)
+}
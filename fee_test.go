@@ -0,0 +1,59 @@
+package multicall
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestFeeContext(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	maxFee, maxPriorityFee := big.NewInt(100e9), big.NewInt(2e9)
+	caller := newTestCaller(t, chain, WithFeeContext(maxFee, maxPriorityFee))
+	if _, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+	msg := chain.msgs[0]
+	if msg.GasFeeCap.Cmp(maxFee) != 0 || msg.GasTipCap.Cmp(maxPriorityFee) != 0 {
+		t.Fatalf("expected fees %s and %s, got %v and %v", maxFee, maxPriorityFee, msg.GasFeeCap, msg.GasTipCap)
+	}
+	if caller.Client() != chain {
+		t.Fatal("expected Client to return the client without fees")
+	}
+
+	plain := newFakeChain(t)
+	if _, err := newTestCaller(t, plain).Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+	if msg := plain.msgs[0]; msg.GasFeeCap != nil || msg.GasTipCap != nil {
+		t.Fatal("expected no fees without a fee context")
+	}
+}
+
+func TestFeeContextPartial(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	maxPriorityFee := big.NewInt(2e9)
+	caller := newTestCaller(t, chain, WithFeeContext(nil, maxPriorityFee))
+
+	// the fee cap of the options is kept
+	maxFee := big.NewInt(50e9)
+	if _, err := caller.CallValue(&bind.TransactOpts{GasFeeCap: maxFee}, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+	if msg := chain.msgs[0]; msg.GasFeeCap.Cmp(maxFee) != 0 || msg.GasTipCap.Cmp(maxPriorityFee) != 0 {
+		t.Fatalf("expected fees %s and %s, got %v and %v", maxFee, maxPriorityFee, msg.GasFeeCap, msg.GasTipCap)
+	}
+
+	// a gas price cannot be sent along with the fees
+	_, err := caller.CallValue(&bind.TransactOpts{GasPrice: big.NewInt(1e9)}, c.NewCall(new(big.Int), "balanceOf", ownerAddress))
+	if err == nil || !strings.Contains(err.Error(), "gas price") {
+		t.Fatalf("expected gas price error, got %v", err)
+	}
+	if chain.calls() != 1 {
+		t.Fatal("expected no eth_call with a gas price")
+	}
+}
@@ -2,7 +2,10 @@ module github.com/pinealctx/multicall
 
 go 1.21.6
 
-require github.com/ethereum/go-ethereum v1.14.7
+require (
+	github.com/ethereum/go-ethereum v1.14.7
+	golang.org/x/time v0.5.0
+)
 
 require (
 	github.com/Microsoft/go-winio v0.6.2 // indirect
@@ -0,0 +1,55 @@
+package multicall
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// GetEthBalance returns the ETH balance of addr read through Multicall3.
+func (caller *Caller) GetEthBalance(opts *bind.CallOpts, addr common.Address) (*big.Int, error) {
+	return caller.read(opts, func(opts *bind.CallOpts) (*big.Int, error) {
+		return caller.contract.GetEthBalance(opts, addr)
+	})
+}
+
+// GetBlockNumber returns the block number read through Multicall3.
+func (caller *Caller) GetBlockNumber(opts *bind.CallOpts) (*big.Int, error) {
+	return caller.read(opts, func(opts *bind.CallOpts) (*big.Int, error) {
+		return caller.contract.GetBlockNumber(opts)
+	})
+}
+
+// GetCurrentBlockTimestamp returns the block timestamp read through Multicall3.
+func (caller *Caller) GetCurrentBlockTimestamp(opts *bind.CallOpts) (*big.Int, error) {
+	return caller.read(opts, func(opts *bind.CallOpts) (*big.Int, error) {
+		return caller.contract.GetCurrentBlockTimestamp(opts)
+	})
+}
+
+// GetBasefee returns the block base fee read through Multicall3.
+func (caller *Caller) GetBasefee(opts *bind.CallOpts) (*big.Int, error) {
+	return caller.read(opts, func(opts *bind.CallOpts) (*big.Int, error) {
+		return caller.contract.GetBasefee(opts)
+	})
+}
+
+// GetChainId returns the chain ID read through Multicall3.
+func (caller *Caller) GetChainId(opts *bind.CallOpts) (*big.Int, error) {
+	return caller.read(opts, func(opts *bind.CallOpts) (*big.Int, error) {
+		return caller.contract.GetChainId(opts)
+	})
+}
+
+// read runs a single read of the multicall contract, retried and rate limited
+// like the multicalls.
+func (caller *Caller) read(opts *bind.CallOpts, fn func(*bind.CallOpts) (*big.Int, error)) (*big.Int, error) {
+	opts = caller.callOpts(opts)
+	var value *big.Int
+	err := caller.retry(opts.Context, func() (err error) {
+		value, err = fn(opts)
+		return err
+	})
+	return value, err
+}
@@ -0,0 +1,98 @@
+package multicall
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// WithBigIntAsString makes JSON decoding encode big integers as JSON strings,
+// which keeps their full precision for any JSON consumer. This is the default.
+func WithBigIntAsString() ContractOption {
+	return func(o *ContractOptions) {
+		o.bigIntAsNumber = false
+	}
+}
+
+// WithBigIntAsNumber makes JSON decoding encode big integers as JSON numbers.
+// The output itself keeps full precision, but consumers parsing numbers into
+// floating point (e.g. JavaScript) lose precision above 2^53.
+func WithBigIntAsNumber() ContractOption {
+	return func(o *ContractOptions) {
+		o.bigIntAsNumber = true
+	}
+}
+
+// UnpackJSON unpacks the outputs into a JSON object keyed by the ABI output
+// names, or by position ("0", "1", ...) for unnamed outputs. Bytes are hex
+// encoded and big integers are encoded as configured on the contract.
+func (call *Call) UnpackJSON(b []byte) ([]byte, error) {
+	out, err := call.unpackOutputs(call.Method, b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
+	}
+
+	obj := make(map[string]any, len(out))
+	for i, output := range call.outputArgs(call.Method) {
+		name := output.Name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		obj[name] = jsonValue(reflect.ValueOf(out[i]), call.Contract.bigIntAsNumber)
+	}
+	return json.Marshal(obj)
+}
+
+// jsonValue converts an unpacked output value into a value marshaling
+// to the expected JSON.
+func jsonValue(v reflect.Value, bigIntAsNumber bool) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if n, ok := v.Interface().(*big.Int); ok {
+		if n == nil {
+			return nil
+		}
+		if bigIntAsNumber {
+			return json.Number(n.String())
+		}
+		return n.String()
+	}
+	if b, ok := bytesOf(v.Interface()); ok {
+		return hexutil.Encode(b)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem(), bigIntAsNumber)
+	case reflect.Slice, reflect.Array:
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i] = jsonValue(v.Index(i), bigIntAsNumber)
+		}
+		return values
+	case reflect.Struct:
+		if _, ok := v.Interface().(json.Marshaler); ok {
+			return v.Interface()
+		}
+		obj := make(map[string]any, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+			obj[name] = jsonValue(v.Field(i), bigIntAsNumber)
+		}
+		return obj
+	}
+	return v.Interface()
+}
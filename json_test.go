@@ -0,0 +1,46 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestUnpackJSONBigInt(t *testing.T) {
+	// 2^256 - 1 loses precision as a float64
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	const digits = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+	tests := []struct {
+		name string
+		opts []ContractOption
+		want string
+	}{
+		{"default", nil, `{"balance":"` + digits + `"}`},
+		{"string", []ContractOption{WithBigIntAsString()}, `{"balance":"` + digits + `"}`},
+		{"number", []ContractOption{WithBigIntAsNumber()}, `{"balance":` + digits + `}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mustContract(t, testABI, tokenAddress, tt.opts...)
+			got, err := c.NewCall(nil, "balanceOf", ownerAddress).UnpackJSON(pack(t, c, "balanceOf", maxUint256))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnpackToMapBigInt(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress, WithBigIntAsNumber())
+	maxUint256 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	out := make(map[string]any)
+	if err := c.NewCall(&out, "balanceOf", ownerAddress).Unpack(pack(t, c, "balanceOf", maxUint256)); err != nil {
+		t.Fatal(err)
+	}
+	if balance, ok := out["balance"].(*big.Int); !ok || balance.Cmp(maxUint256) != 0 {
+		t.Fatalf("expected the exact balance, got %v", out["balance"])
+	}
+}
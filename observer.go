@@ -0,0 +1,24 @@
+package multicall
+
+// Observer receives hooks about the multicalls made by a caller,
+// e.g. for monitoring request and response sizes.
+type Observer interface {
+	// OnBatchEncoded is called before sending a multicall with the total size
+	// of the calldata of its calls.
+	OnBatchEncoded(totalCalldataBytes int)
+	// OnBatchDecoded is called after receiving the multicall results with
+	// the total size of the data returned by its calls.
+	OnBatchDecoded(totalReturnBytes int)
+}
+
+func (caller *Caller) observeEncoded(totalCalldataBytes int) {
+	if caller.observer != nil {
+		caller.observer.OnBatchEncoded(totalCalldataBytes)
+	}
+}
+
+func (caller *Caller) observeDecoded(totalReturnBytes int) {
+	if caller.observer != nil {
+		caller.observer.OnBatchDecoded(totalReturnBytes)
+	}
+}
@@ -0,0 +1,29 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pinealctx/multicall/contract"
+)
+
+// Ping makes a minimal multicall, reading the block number through the multicall
+// contract, and returns its round-trip time. It fails when the node is unreachable
+// or the multicall contract is not deployed at the caller address.
+func (caller *Caller) Ping(ctx context.Context) (time.Duration, error) {
+	multicallABI, err := contract.MulticallMetaData.GetAbi()
+	if err != nil {
+		return 0, err
+	}
+	self := &Contract{abi: multicallABI, address: caller.address}
+	call := self.NewCall(new(big.Int), "getBlockNumber")
+
+	start := time.Now()
+	if _, err := caller.Call(&bind.CallOpts{Context: ctx}, call); err != nil {
+		return 0, fmt.Errorf("ping multicall contract at %s failed: %v", caller.address, err)
+	}
+	return time.Since(start), nil
+}
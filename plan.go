@@ -0,0 +1,158 @@
+package multicall
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// ChunkBy is the measure a ChunkStrategy limits chunks by.
+type ChunkBy int
+
+const (
+	// ChunkByCount limits the number of calls of a chunk.
+	ChunkByCount ChunkBy = iota
+	// ChunkByGas limits the sum of the Gas of the calls of a chunk.
+	ChunkByGas
+	// ChunkBySize limits the size of the aggregate3 calldata of a chunk in bytes.
+	ChunkBySize
+)
+
+// ChunkStrategy describes how PlanBatch splits calls into chunks.
+type ChunkStrategy struct {
+	By ChunkBy
+	// Limit is the maximum number of calls, gas or bytes of a chunk.
+	// Zero means no limit.
+	Limit uint64
+	// Cooldown is the time waited between chunks. Zero falls back to
+	// the caller default set with WithDefaultCooldown.
+	Cooldown time.Duration
+	// AvgChunkLatency is the expected latency of a chunk, used to estimate
+	// the duration of the batch.
+	AvgChunkLatency time.Duration
+}
+
+// ChunkPlan is a chunk of a BatchPlan.
+type ChunkPlan struct {
+	Calls []*Call
+	// Gas is the sum of the Gas of the calls.
+	Gas uint64
+	// Size is the size of the aggregate3 calldata in bytes.
+	Size int
+}
+
+// BatchPlan describes how a batch of calls is executed.
+type BatchPlan struct {
+	Chunks []ChunkPlan
+	// Duration is the estimated duration of the whole batch.
+	Duration time.Duration
+}
+
+// PlanBatch splits the calls into chunks following the strategy and estimates
+// the gas, calldata size and duration of the batch, without any RPC. A single
+// call exceeding the gas or size limit fails the plan.
+func (caller *Caller) PlanBatch(calls []*Call, strategy ChunkStrategy) (BatchPlan, error) {
+	multiCalls, err := packCalls(calls)
+	if err != nil {
+		return BatchPlan{}, err
+	}
+
+	var chunks [][]*Call
+	switch strategy.By {
+	case ChunkByCount:
+		chunkSize := int(strategy.Limit)
+		if limit := caller.CallLimit(); limit > 0 && (chunkSize <= 0 || chunkSize > limit) {
+			chunkSize = limit
+		}
+		chunks = chunkInputs(chunkSize, calls)
+	case ChunkByGas:
+		chunks, err = chunkByWeight(calls, strategy.Limit, 0, "gas", func(i int) uint64 {
+			return calls[i].Gas
+		})
+	case ChunkBySize:
+		chunks, err = chunkByWeight(calls, strategy.Limit, aggregate3BaseSize, "bytes", func(i int) uint64 {
+			return uint64(call3Size(len(multiCalls[i].CallData)))
+		})
+	default:
+		return BatchPlan{}, fmt.Errorf("unknown chunk strategy %d", strategy.By)
+	}
+	if err != nil {
+		return BatchPlan{}, err
+	}
+
+	plan := BatchPlan{Chunks: make([]ChunkPlan, len(chunks))}
+	start := 0
+	for i, chunk := range chunks {
+		size, err := aggregate3Size(multiCalls[start : start+len(chunk)])
+		if err != nil {
+			return BatchPlan{}, err
+		}
+		plan.Chunks[i] = ChunkPlan{Calls: chunk, Gas: TotalGas(chunk), Size: size}
+		start += len(chunk)
+	}
+
+	cooldown := strategy.Cooldown
+	if cooldown == 0 {
+		cooldown = caller.defaultCooldown
+	}
+	if n := len(chunks); n > 0 {
+		plan.Duration = time.Duration(n)*strategy.AvgChunkLatency + time.Duration(n-1)*cooldown
+	}
+	return plan, nil
+}
+
+// chunkByWeight splits the calls in order into chunks whose total weight,
+// starting from the base weight of a chunk, does not exceed the limit.
+func chunkByWeight(calls []*Call, limit, base uint64, unit string, weight func(i int) uint64) ([][]*Call, error) {
+	if limit == 0 {
+		return chunkInputs(0, calls), nil
+	}
+	var (
+		chunks [][]*Call
+		start  int
+		total  = base
+	)
+	for i, call := range calls {
+		w := weight(i)
+		if base+w > limit {
+			return nil, fmt.Errorf("call '%s' at index [%d] needs %d %s, over the chunk limit of %d", call.label(), i, base+w, unit, limit)
+		}
+		if total+w > limit {
+			chunks = append(chunks, calls[start:i])
+			start, total = i, base
+		}
+		total += w
+	}
+	if start < len(calls) {
+		chunks = append(chunks, calls[start:])
+	}
+	return chunks, nil
+}
+
+// call3Size returns the size a call with the given calldata length adds to
+// the aggregate3 calldata: its offset, target, allowFailure, calldata offset,
+// calldata length and padded calldata.
+func call3Size(calldataLen int) int {
+	return 5*32 + (calldataLen+31)/32*32
+}
+
+// aggregate3BaseSize is the size of the aggregate3 calldata without calls:
+// the selector, the array offset and the array length.
+const aggregate3BaseSize = 4 + 2*32
+
+// CallChunkedBySize makes multiple multicalls like CallChunked, but starts a new
+// chunk when the next call would make the aggregate3 calldata exceed
+// maxBytesPerChunk, keeping requests under the size limit of the node whatever
+// the size of the calls.
+func (caller *Caller) CallChunkedBySize(opts *bind.CallOpts, maxBytesPerChunk int, calls ...*Call) ([]*Call, error) {
+	plan, err := caller.PlanBatch(calls, ChunkStrategy{By: ChunkBySize, Limit: uint64(max(maxBytesPerChunk, 0))})
+	if err != nil {
+		return calls, err
+	}
+	chunks := make([][]*Call, len(plan.Chunks))
+	for i, chunk := range plan.Chunks {
+		chunks[i] = chunk.Calls
+	}
+	return caller.callChunks(opts, chunks, 0, 0, nil, calls)
+}
@@ -0,0 +1,108 @@
+package multicall
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// BalanceStrategy is how a Pool assigns chunks to its callers.
+type BalanceStrategy int
+
+const (
+	// RoundRobin assigns chunks to the callers in turn, skipping the callers
+	// still running a chunk.
+	RoundRobin BalanceStrategy = iota
+	// LeastRecentlyUsed assigns chunks to the caller which finished its last
+	// chunk the longest time ago.
+	LeastRecentlyUsed
+)
+
+// Pool spreads multicalls over several callers, typically one per RPC endpoint,
+// to share the load and avoid rate limits.
+type Pool struct {
+	callers  []*Caller
+	strategy BalanceStrategy
+
+	mu   sync.Mutex
+	free *sync.Cond
+	next int
+	// busy tells the callers running a chunk.
+	busy []bool
+	// lastUsed holds when each caller last finished a chunk.
+	lastUsed []time.Time
+}
+
+// NewPool creates a pool of the callers assigning chunks with the strategy.
+func NewPool(strategy BalanceStrategy, callers ...*Caller) (*Pool, error) {
+	if len(callers) == 0 {
+		return nil, errors.New("pool needs at least one caller")
+	}
+	pool := &Pool{
+		callers:  callers,
+		strategy: strategy,
+		busy:     make([]bool, len(callers)),
+		lastUsed: make([]time.Time, len(callers)),
+	}
+	pool.free = sync.NewCond(&pool.mu)
+	return pool, nil
+}
+
+// pick waits for a caller not running a chunk, marks it busy and returns its
+// index. The caller must be given back with release.
+func (pool *Pool) pick() int {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for {
+		i := -1
+		for j := range pool.callers {
+			k := (pool.next + j) % len(pool.callers)
+			if pool.busy[k] {
+				continue
+			}
+			if i < 0 || pool.strategy == LeastRecentlyUsed && pool.lastUsed[k].Before(pool.lastUsed[i]) {
+				i = k
+			}
+			if pool.strategy != LeastRecentlyUsed {
+				break
+			}
+		}
+		if i >= 0 {
+			pool.next = (i + 1) % len(pool.callers)
+			pool.busy[i] = true
+			return i
+		}
+		pool.free.Wait()
+	}
+}
+
+// release marks the caller picked at index i as done with its chunk.
+func (pool *Pool) release(i int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.busy[i] = false
+	pool.lastUsed[i] = time.Now()
+	pool.free.Signal()
+}
+
+// CallChunked makes multiple multicalls by chunking given calls and assigning
+// each chunk to a caller of the pool. Chunks run concurrently, up to one per
+// caller at a time. The first chunk error cancels the chunks not yet finished
+// and is returned. Calls are returned in the given order.
+func (pool *Pool) CallChunked(opts *bind.CallOpts, chunkSize int, calls ...*Call) ([]*Call, error) {
+	resolved := &bind.CallOpts{}
+	if opts != nil {
+		*resolved = *opts
+	}
+	parallel := ParallelOptions{Concurrency: len(pool.callers), FailFast: true}
+	err := runParallel(resolved, chunkInputs(chunkSize, calls), parallel, func(opts *bind.CallOpts, chunk []*Call) error {
+		i := pool.pick()
+		defer pool.release(i)
+		_, err := pool.callers[i].Call(opts, chunk...)
+		return err
+	})
+	return calls, err
+}
@@ -0,0 +1,124 @@
+package multicall
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolRoundRobin(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chains := []*fakeChain{newFakeChain(t), newFakeChain(t), newFakeChain(t)}
+	var callers []*Caller
+	for _, chain := range chains {
+		callers = append(callers, newTestCaller(t, chain))
+	}
+	pool, err := NewPool(RoundRobin, callers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := balanceCalls(c, 18)
+	if _, err := pool.CallChunked(nil, 2, calls...); err != nil {
+		t.Fatal(err)
+	}
+	// callers still running a chunk are skipped, so the spread depends on
+	// timing but every caller gets a chunk
+	total := 0
+	for i, chain := range chains {
+		if chain.calls() == 0 {
+			t.Fatalf("caller %d: expected chunks", i)
+		}
+		total += chain.calls()
+	}
+	if total != 9 {
+		t.Fatalf("expected 9 chunks, got %d", total)
+	}
+	for i, call := range calls {
+		if balance := call.Outputs.(*big.Int); balance.Int64() != int64(i) {
+			t.Fatalf("call %d: unexpected balance %s", i, balance)
+		}
+	}
+}
+
+func TestPoolLeastRecentlyUsed(t *testing.T) {
+	callers := []*Caller{
+		newTestCaller(t, newFakeChain(t)),
+		newTestCaller(t, newFakeChain(t)),
+		newTestCaller(t, newFakeChain(t)),
+	}
+	pool, err := NewPool(LeastRecentlyUsed, callers...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	pool.lastUsed = []time.Time{now.Add(-time.Second), now.Add(-3 * time.Second), now.Add(-2 * time.Second)}
+	for _, want := range []int{1, 2, 0} {
+		if got := pool.pick(); got != want {
+			t.Fatalf("expected caller %d, got %d", want, got)
+		}
+	}
+
+	// the last use is stamped when the chunk is done
+	pool.release(1)
+	pool.release(0)
+	pool.release(2)
+	for _, want := range []int{1, 0, 2} {
+		if got := pool.pick(); got != want {
+			t.Fatalf("expected caller %d, got %d", want, got)
+		}
+	}
+}
+
+func TestPoolSlowCaller(t *testing.T) {
+	for _, strategy := range []BalanceStrategy{RoundRobin, LeastRecentlyUsed} {
+		c := mustContract(t, testABI, tokenAddress)
+		var chains []*fakeChain
+		var callers []*Caller
+		var inFlight, maxInFlight [3]atomic.Int32
+		for i, delay := range []time.Duration{100 * time.Millisecond, time.Millisecond, time.Millisecond} {
+			i, delay := i, delay
+			chain := newFakeChain(t)
+			chain.fail = func(n int, calls []subCall) error {
+				if running := inFlight[i].Add(1); running > maxInFlight[i].Load() {
+					maxInFlight[i].Store(running)
+				}
+				time.Sleep(delay)
+				inFlight[i].Add(-1)
+				return nil
+			}
+			chains = append(chains, chain)
+			callers = append(callers, newTestCaller(t, chain))
+		}
+		pool, err := NewPool(strategy, callers...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		calls := balanceCalls(c, 24)
+		if _, err := pool.CallChunked(nil, 2, calls...); err != nil {
+			t.Fatal(err)
+		}
+		for i := range chains {
+			if got := maxInFlight[i].Load(); got > 1 {
+				t.Fatalf("strategy %d: caller %d ran %d chunks concurrently", strategy, i, got)
+			}
+		}
+		// the slow caller is busy with its first chunk while the others do the rest
+		if got := chains[0].calls(); got > 2 {
+			t.Fatalf("strategy %d: expected the slow caller to get at most 2 of 12 chunks, got %d", strategy, got)
+		}
+		for i, call := range calls {
+			if balance := call.Outputs.(*big.Int); balance.Int64() != int64(i) {
+				t.Fatalf("call %d: unexpected balance %s", i, balance)
+			}
+		}
+	}
+}
+
+func TestNewPoolEmpty(t *testing.T) {
+	if _, err := NewPool(RoundRobin); err == nil {
+		t.Fatal("expected error for an empty pool")
+	}
+}
@@ -0,0 +1,137 @@
+package multicall
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DecodeAll extracts the outputs of calls that share the same output type.
+// Outputs of each call is used when it is a *T (or a T), otherwise the raw
+// return data of the call is decoded into a new T. The returned slices are
+// parallel to the calls: a failed call or an undecodable output leaves the
+// zero value and sets the error at its index.
+func DecodeAll[T any](calls []*Call) ([]T, []error) {
+	values := make([]T, len(calls))
+	errs := make([]error, len(calls))
+	for i, call := range calls {
+		if call.Failed {
+			errs[i] = fmt.Errorf("call '%s' at index [%d] failed", call.Method, i)
+			continue
+		}
+		switch out := call.Outputs.(type) {
+		case *T:
+			if out != nil {
+				values[i] = *out
+				continue
+			}
+		case T:
+			values[i] = out
+			continue
+		}
+		out := new(T)
+		clone := call.Clone()
+		clone.Outputs = out
+		if err := clone.Unpack(call.ReturnData); err != nil {
+			errs[i] = fmt.Errorf("failed to decode call at index [%d] into %T: %v", i, values[i], err)
+			continue
+		}
+		values[i] = *out
+	}
+	return values, errs
+}
+
+// Successful returns the calls that did not fail.
+func Successful(calls []*Call) []*Call {
+	var successful []*Call
+	for _, call := range calls {
+		if !call.Failed {
+			successful = append(successful, call)
+		}
+	}
+	return successful
+}
+
+// FailedCalls returns the calls that failed.
+func FailedCalls(calls []*Call) []*Call {
+	var failed []*Call
+	for _, call := range calls {
+		if call.Failed {
+			failed = append(failed, call)
+		}
+	}
+	return failed
+}
+
+// TotalGas returns the sum of the gas set on the calls, to use as the gas limit
+// when simulating them together.
+func TotalGas(calls []*Call) uint64 {
+	var total uint64
+	for _, call := range calls {
+		total += call.Gas
+	}
+	return total
+}
+
+// DistinctTargets returns the number of distinct contracts targeted by the calls.
+func DistinctTargets(calls []*Call) int {
+	return len(TargetCounts(calls))
+}
+
+// TargetCounts returns the number of calls per target contract address.
+func TargetCounts(calls []*Call) map[common.Address]int {
+	counts := make(map[common.Address]int)
+	for _, call := range calls {
+		counts[call.Contract.address]++
+	}
+	return counts
+}
+
+// MergeByName merges result slices into a map keyed by call name.
+// Unnamed calls are skipped. A name found more than once across all slices
+// is an error.
+func MergeByName(slices ...[]*Call) (map[string]*Call, error) {
+	merged := make(map[string]*Call)
+	for i, calls := range slices {
+		for j, call := range calls {
+			if call.CallName == "" {
+				continue
+			}
+			if _, ok := merged[call.CallName]; ok {
+				return nil, fmt.Errorf("duplicate call name '%s' at slice [%d] index [%d]", call.CallName, i, j)
+			}
+			merged[call.CallName] = call
+		}
+	}
+	return merged, nil
+}
+
+// Results returns the calls keyed by call name. Unnamed calls are skipped and
+// the last call wins when a name is used more than once, use MergeByName to
+// reject duplicates instead.
+func Results(calls []*Call) map[string]*Call {
+	results := make(map[string]*Call, len(calls))
+	for _, call := range calls {
+		if call.CallName != "" {
+			results[call.CallName] = call
+		}
+	}
+	return results
+}
+
+// Batch is a list of calls that can be looked up by name.
+type Batch []*Call
+
+// Get returns the call with the given name. Like Results, the last call wins
+// when a name is used more than once.
+func (batch Batch) Get(name string) (*Call, bool) {
+	if name == "" {
+		return nil, false
+	}
+	for i := len(batch) - 1; i >= 0; i-- {
+		if batch[i].CallName == name {
+			return batch[i], true
+		}
+	}
+	return nil, false
+}
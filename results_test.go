@@ -0,0 +1,57 @@
+package multicall
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDecodeAll(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+
+	type balance struct {
+		Balance *big.Int
+	}
+	calls := []*Call{
+		c.NewCall(new(balance), "balanceOf", ownerAddress),
+		// decoded from the raw return data
+		c.NewCall(new(map[string]any), "balanceOf", tokenAddress),
+		c.NewCall(new(balance), "fail").AllowFailure(),
+	}
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	values, errs := DecodeAll[balance](calls)
+	if errs[0] != nil || values[0].Balance.Int64() != 0xbb {
+		t.Fatalf("call 0: got %+v, %v", values[0], errs[0])
+	}
+	if errs[1] != nil || values[1].Balance.Int64() != 0xaa {
+		t.Fatalf("call 1: got %+v, %v", values[1], errs[1])
+	}
+	if errs[2] == nil || values[2].Balance != nil {
+		t.Fatalf("call 2: expected error, got %+v", values[2])
+	}
+}
+
+func TestMergeByName(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	named := func(name string) *Call {
+		return c.NewCall(new(big.Int), "balanceOf", ownerAddress).Name(name)
+	}
+	a, b, unnamed := named("a"), named("b"), named("")
+
+	merged, err := MergeByName([]*Call{a, unnamed}, []*Call{b}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 2 || merged["a"] != a || merged["b"] != b {
+		t.Fatalf("unexpected merge %v", merged)
+	}
+
+	if _, err := MergeByName([]*Call{a, b}, []*Call{named("b")}); err == nil {
+		t.Fatal("expected error for a name in two slices")
+	}
+	if _, err := MergeByName([]*Call{a, named("a")}); err == nil {
+		t.Fatal("expected error for a name twice in a slice")
+	}
+}
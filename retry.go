@@ -0,0 +1,104 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// IsContractRevert reports whether the error of an eth_call is a contract revert,
+// which is deterministic, as opposed to an error of the node or the transport.
+func IsContractRevert(err error) bool {
+	if err == nil {
+		return false
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return true
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "revert")
+}
+
+// isNodeError reports whether the error of an eth_call is caused by the node
+// and may succeed when retried. Out of gas and too many calls errors are
+// deterministic for a batch and left to the chunking instead.
+func isNodeError(err error) bool {
+	if err == nil || IsContractRevert(err) || isOutOfGas(err) || isTooManyCalls(err) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// rateLimitError is the error of waiting for the rate limit, never retried.
+type rateLimitError struct {
+	err error
+}
+
+func (e *rateLimitError) Error() string {
+	return "rate limit: " + e.err.Error()
+}
+
+func (e *rateLimitError) Unwrap() error {
+	return e.err
+}
+
+// retry runs fn, running it again on retryable errors as many times as configured
+// with WithNodeErrorRetry or WithRetry, waiting for the backoff in between.
+// Context and rate limit errors are never retried.
+func (caller *Caller) retry(ctx context.Context, fn func() error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	retryable := caller.retryable
+	if retryable == nil {
+		retryable = isNodeError
+	}
+	retry := func(err error) bool {
+		var limitErr *rateLimitError
+		return err != nil && ctx.Err() == nil && !errors.As(err, &limitErr) &&
+			!errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && retryable(err)
+	}
+	err := caller.wait(ctx, fn)
+	for attempt := 0; attempt < caller.nodeErrorRetries && retry(err); attempt++ {
+		delay := backoffDelay(caller.retryBackoff, attempt)
+		caller.logf("multicall: retrying in %s after error: %v", delay, err)
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+		err = caller.wait(ctx, fn)
+	}
+	return err
+}
+
+// wait runs fn once the rate limit set with WithRateLimit allows it.
+func (caller *Caller) wait(ctx context.Context, fn func() error) error {
+	if caller.limiter != nil {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		if err := caller.limiter.Wait(ctx); err != nil {
+			return &rateLimitError{err: err}
+		}
+	}
+	return fn()
+}
+
+// backoffDelay returns the delay before the retry following the given attempt:
+// the backoff doubled at every attempt, with a random jitter of up to half of it.
+func backoffDelay(backoff time.Duration, attempt int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	delay := backoff << min(attempt, 16)
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
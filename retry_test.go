@@ -0,0 +1,195 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestIsNodeError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("internal error"), true},
+		{errors.New("connection reset by peer"), true},
+		{errors.New("execution reverted"), false},
+		{&revertError{data: revertData("boom")}, false},
+		{errors.New("gas required exceeds allowance (30000000)"), false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := isNodeError(tt.err); got != tt.want {
+			t.Errorf("isNodeError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestNodeErrorRetry(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+
+	tests := []struct {
+		name  string
+		err   error
+		calls int
+		fails bool
+	}{
+		{"node error", errors.New("internal error"), 2, false},
+		{"contract revert", &revertError{data: revertData("boom")}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newFakeChain(t)
+			chain.fail = func(n int, calls []subCall) error {
+				if n == 0 {
+					return tt.err
+				}
+				return nil
+			}
+			caller := newTestCaller(t, chain, WithNodeErrorRetry(2))
+			balance := new(big.Int)
+			_, err := caller.Call(nil, c.NewCall(balance, "balanceOf", ownerAddress))
+			if tt.fails != (err != nil) {
+				t.Fatalf("expected failure %v, got %v", tt.fails, err)
+			}
+			if chain.calls() != tt.calls {
+				t.Fatalf("expected %d eth_calls, got %d", tt.calls, chain.calls())
+			}
+			if !tt.fails && balance.Int64() != 0xbb {
+				t.Fatalf("unexpected balance %s", balance)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	if got := backoffDelay(0, 3); got != 0 {
+		t.Fatalf("expected no delay without backoff, got %s", got)
+	}
+	backoff := 10 * time.Millisecond
+	for attempt := 0; attempt < 5; attempt++ {
+		full := backoff << attempt
+		for i := 0; i < 100; i++ {
+			if got := backoffDelay(backoff, attempt); got < full/2 || got > full {
+				t.Fatalf("attempt %d: expected a delay within [%s, %s], got %s", attempt, full/2, full, got)
+			}
+		}
+	}
+	// the doubling is capped
+	if got := backoffDelay(time.Nanosecond, 100); got > time.Nanosecond<<16 {
+		t.Fatalf("expected a capped delay, got %s", got)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	failing := func(n int, calls []subCall) error {
+		return errors.New("internal error")
+	}
+
+	tests := []struct {
+		name  string
+		opts  []Option
+		calls int
+	}{
+		{"attempts include the first try", []Option{WithRetry(3, 0)}, 3},
+		{"single attempt", []Option{WithRetry(1, 0)}, 1},
+		{"node error retries", []Option{WithNodeErrorRetry(3)}, 4},
+		{"retry takes precedence", []Option{WithRetry(2, 0), WithNodeErrorRetry(5)}, 2},
+		{"retry takes precedence in any order", []Option{WithNodeErrorRetry(5), WithRetry(2, 0)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newFakeChain(t)
+			chain.fail = failing
+			caller := newTestCaller(t, chain, tt.opts...)
+			if _, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err == nil {
+				t.Fatal("expected error")
+			}
+			if chain.calls() != tt.calls {
+				t.Fatalf("expected %d eth_calls, got %d", tt.calls, chain.calls())
+			}
+		})
+	}
+
+	// the backoff is waited between attempts: at least 5ms then 10ms
+	chain := newFakeChain(t)
+	chain.fail = func(n int, calls []subCall) error {
+		if n < 2 {
+			return errors.New("internal error")
+		}
+		return nil
+	}
+	caller := newTestCaller(t, chain, WithRetry(3, 10*time.Millisecond))
+	start := time.Now()
+	if _, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Fatalf("expected the backoff to be waited, took %s", elapsed)
+	}
+}
+
+func TestRetryPredicate(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	errTryAgain := errors.New("try again")
+	retryable := func(err error) bool {
+		return errors.Is(err, errTryAgain)
+	}
+
+	tests := []struct {
+		name  string
+		err   error
+		calls int
+	}{
+		{"retryable", errTryAgain, 3},
+		{"node error not retryable", errors.New("internal error"), 1},
+		{"context error never retried", context.DeadlineExceeded, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newFakeChain(t)
+			chain.fail = func(n int, calls []subCall) error {
+				return tt.err
+			}
+			caller := newTestCaller(t, chain, WithRetry(3, 0), WithRetryPredicate(func(err error) bool {
+				return retryable(err) || errors.Is(err, context.DeadlineExceeded)
+			}))
+			if _, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err == nil {
+				t.Fatal("expected error")
+			}
+			if chain.calls() != tt.calls {
+				t.Fatalf("expected %d eth_calls, got %d", tt.calls, chain.calls())
+			}
+		})
+	}
+}
+
+func TestRetryRateLimit(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	var predicateCalls int
+	caller := newTestCaller(t, chain, WithRateLimit(0.001, 1), WithRetry(3, 0), WithRetryPredicate(func(error) bool {
+		predicateCalls++
+		return true
+	}))
+	if _, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next token is far beyond the deadline, the limiter fails at once
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := caller.Call(&bind.CallOpts{Context: ctx}, c.NewCall(new(big.Int), "balanceOf", ownerAddress)); err == nil {
+		t.Fatal("expected the rate limit error")
+	}
+	if chain.calls() != 1 || predicateCalls != 0 {
+		t.Fatalf("expected the rate limit error not retried, got %d eth_calls", chain.calls())
+	}
+}
@@ -0,0 +1,83 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// AggregateRevertError is the error of a multicall reverting as a whole, e.g.
+// because a call not allowed to fail reverted. Use errors.As to get it from
+// the error of a multicall.
+type AggregateRevertError struct {
+	// Reason is the decoded revert reason, or the hex revert data when it
+	// cannot be decoded.
+	Reason string
+	// Data is the raw revert data.
+	Data []byte
+}
+
+func (e *AggregateRevertError) Error() string {
+	return "multicall reverted: " + e.Reason
+}
+
+// multicallError wraps the error of a multicall, so that context errors can be
+// matched with errors.Is, decoding the revert data it carries, if any, into an
+// AggregateRevertError.
+func multicallError(err error) error {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if s, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(s); decodeErr == nil {
+				return fmt.Errorf("multicall failed: %w", &AggregateRevertError{
+					Reason: revertReason(nil, data),
+					Data:   data,
+				})
+			}
+		}
+	}
+	return fmt.Errorf("multicall failed: %w", err)
+}
+
+// revertReason decodes revert data as a standard Error(string) or Panic(uint256),
+// or a custom error known by the contract. Undecodable data is returned as hex.
+func revertReason(c *Contract, data []byte) string {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if customErr := decodeCustomError(c, data); customErr != nil {
+		return customErr.Error()
+	}
+	return hexutil.Encode(data)
+}
+
+// CustomError is a custom error declared by the ABI of a contract, or registered
+// with WithErrors, decoded from revert data.
+type CustomError struct {
+	Name string
+	Args []any
+}
+
+func (e *CustomError) Error() string {
+	return fmt.Sprintf("%s%v", e.Name, e.Args)
+}
+
+// decodeCustomError decodes revert data as a custom error known by the contract,
+// returning nil when it is not one.
+func decodeCustomError(c *Contract, data []byte) *CustomError {
+	if c == nil || len(data) < 4 {
+		return nil
+	}
+	errABI, ok := c.errors[[4]byte(data[:4])]
+	if !ok {
+		return nil
+	}
+	args, err := errABI.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil
+	}
+	return &CustomError{Name: errABI.Name, Args: args}
+}
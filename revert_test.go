@@ -0,0 +1,87 @@
+package multicall
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+const vaultABI = `[
+	{"type":"function","name":"withdraw","stateMutability":"view","inputs":[{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"error","name":"InsufficientBalance","inputs":[{"name":"required","type":"uint256"},{"name":"available","type":"uint256"}]}
+]`
+
+// insufficientBalance returns the revert data of InsufficientBalance(required, available).
+func insufficientBalance(t *testing.T, c *Contract, required, available int64) []byte {
+	t.Helper()
+	errABI := c.abi.Errors["InsufficientBalance"]
+	b, err := errABI.Inputs.Pack(big.NewInt(required), big.NewInt(available))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return append(errABI.ID[:4:4], b...)
+}
+
+func TestCallRevertInto(t *testing.T) {
+	c := mustContract(t, vaultABI, tokenAddress)
+	chain := &fakeChain{handle: func(_ *big.Int, call subCall) (bool, []byte) {
+		return false, insufficientBalance(t, c, 100, 40)
+	}}
+	caller := newTestCaller(t, chain)
+
+	type insufficientBalanceError struct {
+		Required  *big.Int
+		Available *big.Int
+	}
+	var revert insufficientBalanceError
+	call := c.NewCall(new(bool), "withdraw", big.NewInt(100)).AllowFailure().DecodeRevert(&revert)
+	if _, err := caller.Call(nil, call); err != nil {
+		t.Fatal(err)
+	}
+	if !call.Failed || !errors.Is(call.Err, ErrCallFailed) {
+		t.Fatalf("expected failed call, got %v", call.Err)
+	}
+	if revert.Required.Int64() != 100 || revert.Available.Int64() != 40 {
+		t.Fatalf("unexpected revert %+v", revert)
+	}
+	if call.CustomError == nil || call.CustomError.Name != "InsufficientBalance" {
+		t.Fatalf("unexpected custom error %v", call.CustomError)
+	}
+}
+
+func TestUnpackRevertUnknown(t *testing.T) {
+	c := mustContract(t, vaultABI, tokenAddress)
+	var revert struct {
+		Required  *big.Int
+		Available *big.Int
+	}
+	call := c.NewCall(new(bool), "withdraw", big.NewInt(100)).DecodeRevert(&revert)
+	if err := call.UnpackRevert(revertData("boom")); !errors.Is(err, ErrUnknownRevert) {
+		t.Fatalf("expected ErrUnknownRevert, got %v", err)
+	}
+}
+
+func TestAggregateRevertError(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+
+	_, err := caller.Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress), c.NewCall(new(big.Int), "fail"))
+	var revertErr *AggregateRevertError
+	if !errors.As(err, &revertErr) {
+		t.Fatalf("expected AggregateRevertError, got %v", err)
+	}
+	if revertErr.Reason != "Multicall3: call failed" || !bytes.Equal(revertErr.Data, revertData("Multicall3: call failed")) {
+		t.Fatalf("unexpected revert %q %x", revertErr.Reason, revertErr.Data)
+	}
+
+	// errors without revert data are not decoded
+	chain := newFakeChain(t)
+	chain.fail = func(n int, calls []subCall) error {
+		return errors.New("connection refused")
+	}
+	_, err = newTestCaller(t, chain).Call(nil, c.NewCall(new(big.Int), "balanceOf", ownerAddress))
+	if err == nil || errors.As(err, &revertErr) {
+		t.Fatalf("expected a plain error, got %v", err)
+	}
+}
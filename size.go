@@ -0,0 +1,49 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pinealctx/multicall/contract"
+)
+
+// ErrRequestTooLarge is returned when the calldata of a multicall exceeds
+// the limit set with WithMaxRequestBytes.
+var ErrRequestTooLarge = errors.New("multicall request too large")
+
+// aggregate3Size returns the size of the aggregate3 calldata of the calls.
+func aggregate3Size(multiCalls []contract.Multicall3Call3) (int, error) {
+	return requestSize("aggregate3", multiCalls)
+}
+
+// requestSize returns the size of the calldata of the multicall method
+// called with the arguments.
+func requestSize(method string, args ...any) (int, error) {
+	multicallABI, err := contract.MulticallMetaData.GetAbi()
+	if err != nil {
+		return 0, err
+	}
+	b, err := multicallABI.Pack(method, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack %s: %v", method, err)
+	}
+	return len(b), nil
+}
+
+// checkRequestSize checks the size of the calldata of the multicall method,
+// called with the arguments to send the calls, against the limit set with
+// WithMaxRequestBytes.
+func (caller *Caller) checkRequestSize(calls int, method string, args ...any) error {
+	if caller.maxRequestBytes <= 0 {
+		return nil
+	}
+	size, err := requestSize(method, args...)
+	if err != nil {
+		return err
+	}
+	if size > caller.maxRequestBytes {
+		return fmt.Errorf("%w: %d calls encode to %d bytes, over the limit of %d bytes, use CallChunked with a smaller chunk size",
+			ErrRequestTooLarge, calls, size, caller.maxRequestBytes)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package multicall
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pinealctx/multicall/contract"
+)
+
+func TestMaxRequestBytes(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	calls := balanceCalls(c, 20)
+	multiCalls, err := packCalls(calls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size, err := aggregate3Size(multiCalls)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain, WithMaxRequestBytes(1024))
+	_, err = caller.Call(nil, calls...)
+	if !errors.Is(err, ErrRequestTooLarge) || !strings.Contains(err.Error(), strconv.Itoa(size)) {
+		t.Fatalf("expected ErrRequestTooLarge with the size %d, got %v", size, err)
+	}
+	if chain.calls() != 0 {
+		t.Fatal("expected no eth_call")
+	}
+
+	if _, err := caller.CallChunked(nil, 2, 0, calls...); err != nil {
+		t.Fatalf("expected small chunks to fit, got %v", err)
+	}
+}
+
+func TestAggregate3Size(t *testing.T) {
+	size, err := aggregate3Size([]contract.Multicall3Call3{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// selector, offset and length of the empty array
+	if size != 4+32+32 {
+		t.Fatalf("unexpected size %d", size)
+	}
+}
+
+func TestMaxRequestBytesPaths(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	tests := []struct {
+		name string
+		call func(caller *Caller, calls []*Call) error
+	}{
+		{"CallTryAggregate", func(caller *Caller, calls []*Call) error {
+			_, err := caller.CallTryAggregate(nil, false, calls...)
+			return err
+		}},
+		{"CallAggregate", func(caller *Caller, calls []*Call) error {
+			_, _, err := caller.CallAggregate(nil, calls...)
+			return err
+		}},
+		{"CallAt", func(caller *Caller, calls []*Call) error {
+			_, _, err := caller.CallAt(nil, calls...)
+			return err
+		}},
+		{"CallValue", func(caller *Caller, calls []*Call) error {
+			_, err := caller.CallValue(nil, calls...)
+			return err
+		}},
+		{"autodetected aggregate", func(caller *Caller, calls []*Call) error {
+			caller.aggregator.Store(int32(Aggregate))
+			_, err := caller.Call(nil, calls...)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newFakeChain(t)
+			caller := newTestCaller(t, chain, WithMaxRequestBytes(1024))
+			if err := tt.call(caller, balanceCalls(c, 20)); !errors.Is(err, ErrRequestTooLarge) {
+				t.Fatalf("expected ErrRequestTooLarge, got %v", err)
+			}
+			if chain.calls() != 0 {
+				t.Fatal("expected no eth_call")
+			}
+			if err := tt.call(caller, balanceCalls(c, 2)); err != nil {
+				t.Fatalf("expected small multicall to fit, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package multicall
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pinealctx/multicall/contract"
+)
+
+// ErrCallTooLarge is returned in auto split mode when a single call
+// runs out of gas even when sent alone.
+var ErrCallTooLarge = errors.New("call runs out of gas even alone")
+
+// isOutOfGas reports whether the error of an eth_call is caused by running out of gas.
+func isOutOfGas(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "out of gas") ||
+		strings.Contains(msg, "gas required exceeds") ||
+		strings.Contains(msg, "exceeds block gas limit")
+}
+
+// tooManyCallsMessages are parts of the errors returned by providers limiting
+// the number of calls in a multicall.
+var tooManyCallsMessages = []string{
+	"too many calls",
+	"too many subcalls",
+	"batch limit",
+	"batch size",
+	"call limit exceeded",
+}
+
+// isTooManyCalls reports whether the error of an eth_call is caused by
+// a provider limit on the number of calls.
+func isTooManyCalls(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, part := range tooManyCallsMessages {
+		if strings.Contains(msg, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// CallLimit returns the maximum number of calls per multicall detected in
+// auto split mode from provider errors, or 0 when no limit was detected.
+// CallChunked uses it as the maximum chunk size.
+func (caller *Caller) CallLimit() int {
+	return int(caller.callLimit.Load())
+}
+
+// lowerCallLimit lowers the detected call limit to the given number of calls.
+func (caller *Caller) lowerCallLimit(limit int) {
+	for {
+		current := caller.callLimit.Load()
+		if current > 0 && current <= int64(limit) {
+			return
+		}
+		if caller.callLimit.CompareAndSwap(current, int64(limit)) {
+			caller.logf("multicall: detected a limit of %d calls", limit)
+			return
+		}
+	}
+}
+
+// callSplitting makes the multicall, splitting the calls in halves and sending
+// each half separately when the multicall runs out of gas or exceeds the
+// provider call limit.
+func (caller *Caller) callSplitting(c contract.Interface, opts *bind.CallOpts, calls []*Call) ([]*Call, error) {
+	if limit := caller.CallLimit(); limit == 0 || len(calls) <= limit {
+		_, err := caller.call(c, opts, calls)
+		switch {
+		case isTooManyCalls(err) && len(calls) > 1:
+			caller.lowerCallLimit(len(calls) / 2)
+		case isOutOfGas(err) && len(calls) == 1:
+			return calls, fmt.Errorf("call '%s': %w", calls[0].label(), ErrCallTooLarge)
+		case !isOutOfGas(err):
+			return calls, err
+		}
+	}
+
+	half := len(calls) / 2
+	caller.logf("multicall: splitting %d calls", len(calls))
+	if _, err := caller.callSplitting(c, opts, calls[:half]); err != nil {
+		return calls, err
+	}
+	if _, err := caller.callSplitting(c, opts, calls[half:]); err != nil {
+		return calls, err
+	}
+	return calls, nil
+}
@@ -0,0 +1,89 @@
+package multicall
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAutoSplitCallTooLarge(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	heavy := c.NewCall(new(big.Int), "name").Name("heavy")
+	heavyData, err := heavy.Pack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := newFakeChain(t)
+	chain.fail = func(n int, calls []subCall) error {
+		for _, call := range calls {
+			if bytes.Equal(call.Data, heavyData) {
+				return errors.New("gas required exceeds allowance (30000000)")
+			}
+		}
+		return nil
+	}
+	caller := newTestCaller(t, chain, WithAutoSplit())
+	calls := append(balanceCalls(c, 3), heavy)
+	_, err = caller.Call(nil, calls...)
+	if !errors.Is(err, ErrCallTooLarge) || !strings.Contains(err.Error(), "'heavy'") {
+		t.Fatalf("expected ErrCallTooLarge naming the heavy call, got %v", err)
+	}
+	// 4 calls, then the 2 halves, then the heavy call alone
+	if got := chain.sizes(); len(got) > 5 {
+		t.Fatalf("expected the split to stop at the heavy call, got %v", got)
+	}
+	if balance := calls[1].Outputs.(*big.Int); balance.Int64() != 1 {
+		t.Fatalf("expected the first half to be decoded, got balance %s", balance)
+	}
+}
+
+func TestAutoSplitAlwaysOutOfGas(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	chain.fail = func(n int, calls []subCall) error {
+		return errors.New("out of gas")
+	}
+	caller := newTestCaller(t, chain, WithAutoSplit())
+	_, err := caller.Call(nil, balanceCalls(c, 8)...)
+	if !errors.Is(err, ErrCallTooLarge) {
+		t.Fatalf("expected ErrCallTooLarge, got %v", err)
+	}
+	// 8, 4, 2 then 1 call, stopping at the first call too large
+	if got := chain.sizes(); len(got) != 4 || got[3] != 1 {
+		t.Fatalf("unexpected multicalls %v", got)
+	}
+}
+
+func TestAutoSplitTooManyCalls(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	chain.fail = func(n int, calls []subCall) error {
+		if n == 0 {
+			return errors.New("too many calls in batch")
+		}
+		return nil
+	}
+	caller := newTestCaller(t, chain, WithAutoSplit())
+	calls := balanceCalls(c, 8)
+	if _, err := caller.Call(nil, calls...); err != nil {
+		t.Fatal(err)
+	}
+	if got := caller.CallLimit(); got != 4 {
+		t.Fatalf("expected a detected limit of 4 calls, got %d", got)
+	}
+	if balance := calls[7].Outputs.(*big.Int); balance.Int64() != 7 {
+		t.Fatalf("unexpected balance %s", balance)
+	}
+
+	// later chunks are limited to the detected limit
+	if _, err := caller.CallChunked(nil, 10, 0, balanceCalls(c, 8)...); err != nil {
+		t.Fatal(err)
+	}
+	if got := chain.sizes(); !reflect.DeepEqual(got, []int{8, 4, 4, 4, 4}) {
+		t.Fatalf("unexpected multicalls %v", got)
+	}
+}
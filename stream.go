@@ -0,0 +1,156 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// UnpackStream unpacks the outputs of a method returning a single array and
+// sends its elements to ch one by one, instead of setting them to Outputs.
+// Elements are decoded from the return data as they are sent, so only one is
+// held at a time besides the data. The channel is closed when decoding
+// completes, also on error. A send blocked on a consumer no longer reading
+// is abandoned when ctx is done, returning the context error.
+func (call *Call) UnpackStream(ctx context.Context, b []byte, ch chan<- any) error {
+	defer close(ch)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	outputs := call.outputArgs(call.Method)
+	if len(outputs) != 1 {
+		return fmt.Errorf("method '%s' returns %d values, not a single array", call.Method, len(outputs))
+	}
+	typ := outputs[0].Type
+	if typ.T != abi.SliceTy && typ.T != abi.ArrayTy {
+		return fmt.Errorf("method '%s' returns %s, not an array", call.Method, typ)
+	}
+
+	// the elements start after the length of a dynamic array, at the offset
+	// of an array with dynamic elements, or in place otherwise
+	start, length := 0, typ.Size
+	if isDynamicType(typ) {
+		offset, err := readWord(b, 0)
+		if err != nil {
+			return fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
+		}
+		start = offset
+	}
+	if typ.T == abi.SliceTy {
+		n, err := readWord(b, start)
+		if err != nil {
+			return fmt.Errorf("failed to unpack '%s' outputs: %v", call.Method, err)
+		}
+		start, length = start+32, n
+	}
+
+	elem := *typ.Elem
+	elemArgs := abi.Arguments{{Type: elem}}
+	dynamic := isDynamicType(elem)
+	size := 32
+	if !dynamic {
+		size = staticSize(elem)
+	}
+	if length > (len(b)-start)/size {
+		return fmt.Errorf("failed to unpack '%s' outputs: %d elements overflow %d bytes of data", call.Method, length, len(b))
+	}
+
+	for i := 0; i < length; i++ {
+		value, err := unpackElement(b, start, i, length, size, dynamic, elemArgs)
+		if err != nil {
+			return fmt.Errorf("failed to unpack '%s' element [%d]: %v", call.Method, i, err)
+		}
+		select {
+		case ch <- value:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// unpackElement unpacks the i-th of the n elements of an array starting at
+// start in the data. Static elements are unpacked in place, dynamic elements
+// from their offset relative to start, up to the offset of the next element.
+func unpackElement(b []byte, start, i, n, size int, dynamic bool, elemArgs abi.Arguments) (any, error) {
+	pos := start + i*size
+	if !dynamic {
+		out, err := elemArgs.Unpack(b[pos : pos+size])
+		if err != nil {
+			return nil, err
+		}
+		return out[0], nil
+	}
+
+	offset, err := readWord(b, pos)
+	if err != nil {
+		return nil, err
+	}
+	end := len(b)
+	if i+1 < n {
+		if next, err := readWord(b, pos+32); err == nil && start+next > start+offset {
+			end = min(start+next, len(b))
+		}
+	}
+	if start+offset > end {
+		return nil, fmt.Errorf("offset %d out of range", offset)
+	}
+	// re-encode the element alone, behind the offset of a single argument
+	data := make([]byte, 32, 32+end-start-offset)
+	data[31] = 32
+	out, err := elemArgs.Unpack(append(data, b[start+offset:end]...))
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// readWord reads the 32-byte word at pos as an offset or a length,
+// which must fit within the data.
+func readWord(b []byte, pos int) (int, error) {
+	if pos < 0 || pos+32 > len(b) {
+		return 0, fmt.Errorf("word at %d out of range of %d bytes", pos, len(b))
+	}
+	word := new(big.Int).SetBytes(b[pos : pos+32])
+	if !word.IsInt64() || word.Int64() > int64(len(b)) {
+		return 0, errors.New("offset or length larger than the data")
+	}
+	return int(word.Int64()), nil
+}
+
+// isDynamicType reports whether the ABI encoding of the type is dynamic,
+// i.e. referenced by an offset.
+func isDynamicType(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy:
+		return true
+	case abi.ArrayTy:
+		return isDynamicType(*t.Elem)
+	case abi.TupleTy:
+		for _, elem := range t.TupleElems {
+			if isDynamicType(*elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// staticSize returns the size of the ABI encoding of a static type.
+func staticSize(t abi.Type) int {
+	switch t.T {
+	case abi.ArrayTy:
+		return t.Size * staticSize(*t.Elem)
+	case abi.TupleTy:
+		size := 0
+		for _, elem := range t.TupleElems {
+			size += staticSize(*elem)
+		}
+		return size
+	}
+	return 32
+}
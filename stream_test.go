@@ -0,0 +1,101 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+)
+
+const streamABI = `[
+	{"type":"function","name":"balances","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256[]"}]},
+	{"type":"function","name":"names","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string[]"}]},
+	{"type":"function","name":"top","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256[3]"}]},
+	{"type":"function","name":"pairs","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"tuple[]","components":[{"name":"id","type":"uint256"},{"name":"label","type":"string"}]}]},
+	{"type":"function","name":"total","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]}
+]`
+
+// collect unpacks the stream of the method outputs and returns the elements.
+func collect(t *testing.T, c *Contract, method string, data []byte) ([]any, error) {
+	t.Helper()
+	ch := make(chan any)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.NewCall(nil, method).UnpackStream(context.Background(), data, ch)
+	}()
+	var values []any
+	for value := range ch {
+		values = append(values, value)
+	}
+	return values, <-errc
+}
+
+func TestUnpackStream(t *testing.T) {
+	c := mustContract(t, streamABI, tokenAddress)
+	pair := c.abi.Methods["pairs"].Outputs[0].Type.GetType().Elem()
+
+	pairs := reflect.MakeSlice(reflect.SliceOf(pair), 2, 2)
+	for i, label := range []string{"a", "bc"} {
+		pairs.Index(i).Field(0).Set(reflect.ValueOf(big.NewInt(int64(i + 1))))
+		pairs.Index(i).Field(1).SetString(label)
+	}
+	tests := []struct {
+		method string
+		value  any
+	}{
+		{"balances", []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}},
+		{"balances", []*big.Int{}},
+		{"names", []string{"first", "", "a much longer name spanning more than a single word"}},
+		{"top", [3]*big.Int{big.NewInt(7), big.NewInt(8), big.NewInt(9)}},
+		{"pairs", pairs.Interface()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			values, err := collect(t, c, tt.method, pack(t, c, tt.method, tt.value))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := reflect.ValueOf(tt.value)
+			if len(values) != want.Len() {
+				t.Fatalf("expected %d elements, got %d", want.Len(), len(values))
+			}
+			for i, value := range values {
+				if !reflect.DeepEqual(value, want.Index(i).Interface()) {
+					t.Fatalf("element %d: expected %v, got %v", i, want.Index(i), value)
+				}
+			}
+		})
+	}
+
+	if _, err := collect(t, c, "total", pack(t, c, "total", big.NewInt(1))); err == nil {
+		t.Fatal("expected error for a non array output")
+	}
+	data := pack(t, c, "balances", []*big.Int{big.NewInt(1), big.NewInt(2)})
+	if _, err := collect(t, c, "balances", data[:len(data)-32]); err == nil {
+		t.Fatal("expected error for truncated data")
+	}
+}
+
+func TestUnpackStreamCancel(t *testing.T) {
+	c := mustContract(t, streamABI, tokenAddress)
+	data := pack(t, c, "balances", []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan any)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.NewCall(nil, "balances").UnpackStream(ctx, data, ch)
+	}()
+	// the consumer stops reading after the first element
+	if value := <-ch; value.(*big.Int).Int64() != 1 {
+		t.Fatalf("unexpected first element %v", value)
+	}
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancellation, got %v", err)
+	}
+	if _, ok := <-ch; ok {
+		t.Fatal("expected the channel closed")
+	}
+}
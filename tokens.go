@@ -0,0 +1,237 @@
+package multicall
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const erc1155ABIJSON = `[
+	{
+		"inputs": [
+			{"internalType": "address[]", "name": "accounts", "type": "address[]"},
+			{"internalType": "uint256[]", "name": "ids", "type": "uint256[]"}
+		],
+		"name": "balanceOfBatch",
+		"outputs": [{"internalType": "uint256[]", "name": "", "type": "uint256[]"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+const erc20ABIJSON = `[
+	{
+		"inputs": [{"internalType": "address", "name": "account", "type": "address"}],
+		"name": "balanceOf",
+		"outputs": [{"internalType": "uint256", "name": "", "type": "uint256"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "decimals",
+		"outputs": [{"internalType": "uint8", "name": "", "type": "uint8"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "name",
+		"outputs": [{"internalType": "string", "name": "", "type": "string"}],
+		"stateMutability": "view",
+		"type": "function"
+	},
+	{
+		"inputs": [],
+		"name": "symbol",
+		"outputs": [{"internalType": "string", "name": "", "type": "string"}],
+		"stateMutability": "view",
+		"type": "function"
+	}
+]`
+
+// erc20RawABIJSON declares the ERC20 metadata methods without outputs, so that
+// their calls never fail to decode: old tokens return bytes32 instead of string
+// and non-token contracts return nothing. The raw data is decoded afterwards.
+const erc20RawABIJSON = `[
+	{"inputs": [], "name": "decimals", "outputs": [], "stateMutability": "view", "type": "function"},
+	{"inputs": [], "name": "name", "outputs": [], "stateMutability": "view", "type": "function"},
+	{"inputs": [], "name": "symbol", "outputs": [], "stateMutability": "view", "type": "function"}
+]`
+
+var (
+	erc1155ABI  = mustParseABI(erc1155ABIJSON)
+	erc20ABI    = mustParseABI(erc20ABIJSON)
+	erc20RawABI = mustParseABI(erc20RawABIJSON)
+)
+
+func mustParseABI(abiJSON string) *abi.ABI {
+	parsed, err := ParseABI(abiJSON)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Bytes32ToString converts a bytes32 value into a string, trimming the trailing
+// zero padding, as used by old ERC20 tokens for their name and symbol.
+// Invalid UTF-8 sequences are dropped.
+func Bytes32ToString(b [32]byte) string {
+	return bytesToString(b[:])
+}
+
+func bytesToString(b []byte) string {
+	return strings.ToValidUTF8(string(bytes.TrimRight(b, "\x00")), "")
+}
+
+// ERC1155BalanceOfBatchOutput is the output of an ERC1155 balanceOfBatch call.
+type ERC1155BalanceOfBatchOutput struct {
+	Balances []*big.Int
+}
+
+// ERC1155BalanceOfBatch creates a balanceOfBatch call to an ERC1155 token,
+// reading the balance of owners[i] for ids[i]. Its outputs are an
+// *ERC1155BalanceOfBatchOutput. Owners and ids must have the same length,
+// otherwise packing the call fails.
+func ERC1155BalanceOfBatch(token common.Address, owners []common.Address, ids []*big.Int) *Call {
+	call := (&Contract{abi: erc1155ABI, address: token}).NewCall(new(ERC1155BalanceOfBatchOutput), "balanceOfBatch", owners, ids)
+	if len(owners) != len(ids) {
+		call.err = fmt.Errorf("got %d owners but %d ids", len(owners), len(ids))
+	}
+	return call
+}
+
+type erc20BalanceOutput struct {
+	Balance *big.Int
+}
+
+type erc20DecimalsOutput struct {
+	Decimals uint8
+}
+
+// ERC20Balances reads the balances of the owners of an ERC20 token
+// in a single multicall.
+func (caller *Caller) ERC20Balances(opts *bind.CallOpts, token common.Address, owners []common.Address) (map[common.Address]*big.Int, error) {
+	balances, _, err := caller.erc20Balances(opts, token, owners, false)
+	return balances, err
+}
+
+// ERC20ScaledBalances reads the balances of the owners of an ERC20 token
+// along with its decimals in a single multicall, and returns the balances
+// scaled by the decimals.
+func (caller *Caller) ERC20ScaledBalances(opts *bind.CallOpts, token common.Address, owners []common.Address) (map[common.Address]*big.Float, error) {
+	balances, decimals, err := caller.erc20Balances(opts, token, owners, true)
+	if err != nil {
+		return nil, err
+	}
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	scaled := make(map[common.Address]*big.Float, len(balances))
+	for owner, balance := range balances {
+		scaled[owner] = new(big.Float).Quo(new(big.Float).SetInt(balance), unit)
+	}
+	return scaled, nil
+}
+
+func (caller *Caller) erc20Balances(opts *bind.CallOpts, token common.Address, owners []common.Address, withDecimals bool) (map[common.Address]*big.Int, uint8, error) {
+	tokenContract := &Contract{abi: erc20ABI, address: token}
+	calls := make([]*Call, 0, len(owners)+1)
+	for _, owner := range owners {
+		calls = append(calls, tokenContract.NewCall(new(erc20BalanceOutput), "balanceOf", owner))
+	}
+	if withDecimals {
+		calls = append(calls, tokenContract.NewCall(new(erc20DecimalsOutput), "decimals"))
+	}
+	if _, err := caller.Call(opts, calls...); err != nil {
+		return nil, 0, err
+	}
+
+	balances := make(map[common.Address]*big.Int, len(owners))
+	for i, owner := range owners {
+		balances[owner] = calls[i].Outputs.(*erc20BalanceOutput).Balance
+	}
+	var decimals uint8
+	if withDecimals {
+		decimals = calls[len(owners)].Outputs.(*erc20DecimalsOutput).Decimals
+	}
+	return balances, decimals, nil
+}
+
+// TokenInfo is the metadata of an ERC20 token.
+type TokenInfo struct {
+	Name     string
+	Symbol   string
+	Decimals uint8
+	// Missing lists the fields ("name", "symbol", "decimals") that could not be read.
+	Missing []string
+}
+
+var erc20MetadataMethods = []string{"name", "symbol", "decimals"}
+
+// ERC20Metadata reads the name, symbol and decimals of the tokens in a single
+// multicall. Names and symbols returned as bytes32 by old tokens are supported.
+// A field failing to be read does not fail the others, it is listed in
+// TokenInfo.Missing instead.
+func (caller *Caller) ERC20Metadata(opts *bind.CallOpts, tokens []common.Address) (map[common.Address]TokenInfo, error) {
+	calls := make([]*Call, 0, len(tokens)*len(erc20MetadataMethods))
+	for _, token := range tokens {
+		tokenContract := &Contract{abi: erc20RawABI, address: token}
+		for _, method := range erc20MetadataMethods {
+			calls = append(calls, tokenContract.NewCall(new(struct{}), method).AllowFailure())
+		}
+	}
+	if _, err := caller.Call(opts, calls...); err != nil {
+		return nil, err
+	}
+
+	infos := make(map[common.Address]TokenInfo, len(tokens))
+	for i, token := range tokens {
+		var info TokenInfo
+		for j, method := range erc20MetadataMethods {
+			call := calls[i*len(erc20MetadataMethods)+j]
+			var ok bool
+			if !call.Failed {
+				switch method {
+				case "name":
+					info.Name, ok = decodeTokenText(method, call.ReturnData)
+				case "symbol":
+					info.Symbol, ok = decodeTokenText(method, call.ReturnData)
+				case "decimals":
+					info.Decimals, ok = decodeTokenDecimals(call.ReturnData)
+				}
+			}
+			if !ok {
+				info.Missing = append(info.Missing, method)
+			}
+		}
+		infos[token] = info
+	}
+	return infos, nil
+}
+
+// decodeTokenText decodes the string returned by the method, falling back to
+// bytes32 for old tokens.
+func decodeTokenText(method string, data []byte) (string, bool) {
+	if out, err := erc20ABI.Unpack(method, data); err == nil {
+		if s, ok := out[0].(string); ok {
+			return s, true
+		}
+	}
+	if len(data) == 32 {
+		return Bytes32ToString([32]byte(data)), true
+	}
+	return "", false
+}
+
+func decodeTokenDecimals(data []byte) (uint8, bool) {
+	out, err := erc20ABI.Unpack("decimals", data)
+	if err != nil {
+		return 0, false
+	}
+	decimals, ok := out[0].(uint8)
+	return decimals, ok
+}
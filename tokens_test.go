@@ -0,0 +1,72 @@
+package multicall
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func bytes32(s string) [32]byte {
+	var b [32]byte
+	copy(b[:], s)
+	return b
+}
+
+func TestBytes32ToString(t *testing.T) {
+	tests := []struct {
+		in   [32]byte
+		want string
+	}{
+		{bytes32("USDC"), "USDC"},
+		{bytes32("Maker"), "Maker"},
+		{bytes32(""), ""},
+		{bytes32("AB\xffC"), "ABC"},
+	}
+	for _, tt := range tests {
+		if got := Bytes32ToString(tt.in); got != tt.want {
+			t.Errorf("Bytes32ToString(%x) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestERC20Metadata(t *testing.T) {
+	usdc := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	mkr := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	chain := &fakeChain{handle: func(_ *big.Int, call subCall) (bool, []byte) {
+		method, err := erc20ABI.MethodById(call.Data)
+		if err != nil {
+			return false, nil
+		}
+		var b []byte
+		switch {
+		case call.Target == mkr && method.Name == "decimals":
+			return false, revertData("no decimals")
+		case call.Target == mkr:
+			text := bytes32(map[string]string{"name": "Maker", "symbol": "MKR"}[method.Name])
+			return true, text[:]
+		case method.Name == "decimals":
+			b, err = method.Outputs.Pack(uint8(6))
+		default:
+			b, err = method.Outputs.Pack(map[string]string{"name": "USD Coin", "symbol": "USDC"}[method.Name])
+		}
+		if err != nil {
+			t.Error(err)
+		}
+		return true, b
+	}}
+	caller := newTestCaller(t, chain)
+
+	infos, err := caller.ERC20Metadata(nil, []common.Address{usdc, mkr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[common.Address]TokenInfo{
+		usdc: {Name: "USD Coin", Symbol: "USDC", Decimals: 6},
+		mkr:  {Name: "Maker", Symbol: "MKR", Missing: []string{"decimals"}},
+	}
+	if !reflect.DeepEqual(infos, want) {
+		t.Fatalf("expected %+v, got %+v", want, infos)
+	}
+}
@@ -0,0 +1,73 @@
+package multicall
+
+import "github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+// Run calls the contract method once per inputs set in a single multicall
+// and returns the outputs decoded into T, in the order of the inputs.
+func Run[T any](caller *Caller, opts *bind.CallOpts, method string, contract *Contract, inputsList [][]any) ([]T, error) {
+	calls := make([]*Call, len(inputsList))
+	for i, inputs := range inputsList {
+		calls[i] = contract.NewCall(new(T), method, inputs...)
+	}
+	if _, err := caller.Call(opts, calls...); err != nil {
+		return nil, err
+	}
+
+	values := make([]T, len(calls))
+	for i, call := range calls {
+		values[i] = *call.Outputs.(*T)
+	}
+	return values, nil
+}
+
+// TypedCall is a call whose outputs are decoded into a T, read with Result.
+// The embedded Call is passed to the caller like any other call.
+type TypedCall[T any] struct {
+	*Call
+	out *T
+}
+
+// NewTypedCall creates a new call of the contract method decoding into a T.
+func NewTypedCall[T any](contract *Contract, method string, inputs ...any) *TypedCall[T] {
+	out := new(T)
+	return &TypedCall[T]{Call: contract.NewCall(out, method, inputs...), out: out}
+}
+
+// Result returns the outputs decoded by the last invocation.
+func (call *TypedCall[T]) Result() T {
+	return *call.out
+}
+
+// Result is the outcome of a call decoded into a T.
+type Result[T any] struct {
+	Value   T
+	Success bool
+	// Err is the error of the call: ErrCallFailed when it failed, the decoding
+	// error, or the multicall error when the call was not received.
+	Err error
+}
+
+// RunTyped makes a multicall of the calls, decoding each into a T set as its
+// outputs, and returns a result per call in the order of the calls. Failed
+// calls and decoding errors are reported per result instead of aborting.
+func RunTyped[T any](caller *Caller, opts *bind.CallOpts, calls []*Call) []Result[T] {
+	outputs := make([]*T, len(calls))
+	for i, call := range calls {
+		outputs[i] = new(T)
+		call.Outputs = outputs[i]
+	}
+	_, callErr := caller.Call(opts, calls...)
+
+	results := make([]Result[T], len(calls))
+	for i, call := range calls {
+		switch {
+		case call.UpdatedAt.IsZero() && callErr != nil:
+			results[i].Err = callErr
+		case call.Err != nil:
+			results[i].Err = call.Err
+		default:
+			results[i] = Result[T]{Value: *outputs[i], Success: true}
+		}
+	}
+	return results
+}
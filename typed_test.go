@@ -0,0 +1,72 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type balanceOutput struct {
+	Balance *big.Int
+}
+
+func TestRun(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+
+	owners := []common.Address{ownerAddress, tokenAddress, {}}
+	inputsList := make([][]any, len(owners))
+	for i, owner := range owners {
+		inputsList[i] = []any{owner}
+	}
+	balances, err := Run[balanceOutput](caller, nil, "balanceOf", c, inputsList)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []int64{0xbb, 0xaa, 0} {
+		if balances[i].Balance.Int64() != want {
+			t.Fatalf("balance %d: expected %d, got %s", i, want, balances[i].Balance)
+		}
+	}
+	if chain.calls() != 1 {
+		t.Fatalf("expected a single multicall, got %d", chain.calls())
+	}
+
+	if _, err := Run[balanceOutput](caller, nil, "fail", c, [][]any{{}}); err == nil {
+		t.Fatal("expected error for a failing call")
+	}
+}
+
+func TestRunTyped(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	caller := newTestCaller(t, newFakeChain(t))
+
+	calls := []*Call{
+		c.NewCall(nil, "balanceOf", ownerAddress),
+		c.NewCall(nil, "fail").AllowFailure(),
+		c.NewCall(nil, "balanceOf", tokenAddress),
+	}
+	results := RunTyped[*big.Int](caller, nil, calls)
+	if len(results) != len(calls) {
+		t.Fatalf("expected %d results, got %d", len(calls), len(results))
+	}
+	for i, want := range map[int]int64{0: 0xbb, 2: 0xaa} {
+		if result := results[i]; !result.Success || result.Err != nil || result.Value.Int64() != want {
+			t.Fatalf("result %d: expected %d, got %+v", i, want, result)
+		}
+	}
+	if result := results[1]; result.Success || !errors.Is(result.Err, ErrCallFailed) {
+		t.Fatalf("expected ErrCallFailed, got %+v", result)
+	}
+
+	// a multicall error is set to every result
+	results = RunTyped[*big.Int](caller, nil, []*Call{c.NewCall(nil, "fail"), c.NewCall(nil, "balanceOf", ownerAddress)})
+	for i, result := range results {
+		if result.Success || result.Err == nil {
+			t.Fatalf("result %d: expected error, got %+v", i, result)
+		}
+	}
+}
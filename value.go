@@ -0,0 +1,158 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/pinealctx/multicall/contract"
+)
+
+// ErrValueMismatch is returned by CallValue when the values of the calls
+// do not add up to the value of the transact options.
+var ErrValueMismatch = errors.New("sum of call values does not match opts value")
+
+// CallValue makes a multicall with aggregate3Value, sending the Value of every
+// call along with it, e.g. to simulate payable functions. The call is simulated
+// with eth_call from opts.From, the values must add up to opts.Value as Multicall3
+// requires. Defaults of the caller (e.g. the pinned block) are applied.
+// Without opts.GasLimit, the gas limit of the simulation is the TotalGas of the
+// calls with the overhead of the multicall added, see simulationGas, or left to
+// the node when no call has its Gas set.
+func (caller *Caller) CallValue(opts *bind.TransactOpts, calls ...*Call) ([]*Call, error) {
+	if opts == nil {
+		opts = &bind.TransactOpts{}
+	}
+	total := new(big.Int)
+	for _, call := range calls {
+		if call.Value != nil {
+			total.Add(total, call.Value)
+		}
+	}
+	value := opts.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	if total.Cmp(value) != 0 {
+		return calls, fmt.Errorf("%w: calls send %s wei, opts value is %s wei", ErrValueMismatch, total, value)
+	}
+
+	if err := caller.validate(calls); err != nil {
+		return calls, err
+	}
+	resetCalls(calls)
+	multiCalls, err := packCalls(calls)
+	if err != nil {
+		return calls, err
+	}
+	valueCalls := make([]contract.Multicall3Call3Value, len(multiCalls))
+	var calldataBytes int
+	for i, multiCall := range multiCalls {
+		callValue := calls[i].Value
+		if callValue == nil {
+			callValue = new(big.Int)
+		}
+		valueCalls[i] = contract.Multicall3Call3Value{
+			Target:       multiCall.Target,
+			AllowFailure: multiCall.AllowFailure,
+			Value:        callValue,
+			CallData:     multiCall.CallData,
+		}
+		calldataBytes += len(multiCall.CallData)
+	}
+	if err := caller.checkRequestSize(len(valueCalls), "aggregate3Value", valueCalls); err != nil {
+		return calls, err
+	}
+	caller.observeEncoded(calldataBytes)
+	gas := opts.GasLimit
+	if gas == 0 {
+		gas = simulationGas(calls)
+	}
+
+	caller.logf("multicall: sending %d calls with %s wei", len(valueCalls), total)
+	var results []contract.Multicall3Result
+	err = caller.retry(opts.Context, func() (err error) {
+		results, err = caller.aggregate3Value(opts, gas, valueCalls)
+		return err
+	})
+	if err != nil {
+		caller.logf("multicall: failed: %v", err)
+		return calls, multicallError(err)
+	}
+
+	var returnBytes int
+	for _, result := range results {
+		returnBytes += len(result.ReturnData)
+	}
+	caller.observeDecoded(returnBytes)
+
+	if err := unpackResults(calls, results, caller.onResult); err != nil {
+		return calls, err
+	}
+	return calls, nil
+}
+
+const (
+	// multicallBaseGas covers the intrinsic gas of the transaction, its calldata
+	// and the decoding and encoding done by Multicall3.
+	multicallBaseGas = 100_000
+	// multicallCallGas covers the loop of Multicall3 for every call.
+	multicallCallGas = 10_000
+)
+
+// simulationGas returns the gas limit to simulate the calls with: the TotalGas
+// of the calls raised by 64/63, as Multicall3 forwards at most 63/64 of its
+// remaining gas to a call, plus the overhead of the multicall. It returns 0,
+// leaving the gas limit to the node, when no call has its Gas set.
+func simulationGas(calls []*Call) uint64 {
+	total := TotalGas(calls)
+	if total == 0 {
+		return 0
+	}
+	return total*64/63 + multicallBaseGas + uint64(len(calls))*multicallCallGas
+}
+
+// aggregate3Value simulates aggregate3Value with eth_call. The generated binding
+// cannot be used since CallOpts has no value.
+func (caller *Caller) aggregate3Value(opts *bind.TransactOpts, gas uint64, valueCalls []contract.Multicall3Call3Value) ([]contract.Multicall3Result, error) {
+	multicallABI, err := contract.MulticallMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	data, err := multicallABI.Pack("aggregate3Value", valueCalls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack aggregate3Value: %v", err)
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = caller.ctx
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	msg := ethereum.CallMsg{
+		From:      opts.From,
+		To:        &caller.address,
+		Gas:       gas,
+		GasPrice:  opts.GasPrice,
+		GasFeeCap: opts.GasFeeCap,
+		GasTipCap: opts.GasTipCap,
+		Value:     opts.Value,
+		Data:      data,
+	}
+	output, err := caller.client.CallContract(ctx, msg, caller.blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := multicallABI.Unpack("aggregate3Value", output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack aggregate3Value: %v", err)
+	}
+	return *abi.ConvertType(out[0], new([]contract.Multicall3Result)).(*[]contract.Multicall3Result), nil
+}
@@ -0,0 +1,61 @@
+package multicall
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestCallValueGas(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+
+	tests := []struct {
+		name     string
+		gasLimit uint64
+		gas      uint64
+		want     uint64
+	}{
+		// 300k * 64/63 + 100k base + 2 * 10k per call
+		{"total of calls with overhead", 0, 100_000, 424_761},
+		{"opts gas limit", 1_000_000, 100_000, 1_000_000},
+		{"left to the node", 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := newFakeChain(t)
+			caller := newTestCaller(t, chain)
+			calls := []*Call{
+				c.NewCall(new(big.Int), "balanceOf", ownerAddress).WithGas(tt.gas),
+				c.NewCall(new(big.Int), "balanceOf", ownerAddress).WithGas(2 * tt.gas),
+			}
+			calls[0].Value = big.NewInt(1)
+			opts := &bind.TransactOpts{Value: big.NewInt(1), GasLimit: tt.gasLimit}
+			if _, err := caller.CallValue(opts, calls...); err != nil {
+				t.Fatal(err)
+			}
+			if got := chain.msgs[0].Gas; got != tt.want {
+				t.Fatalf("expected gas %d, got %d", tt.want, got)
+			}
+			if got := chain.sent[0][0].Value; got.Int64() != 1 {
+				t.Fatalf("expected value 1 on the first call, got %s", got)
+			}
+		})
+	}
+}
+
+func TestCallValueMismatch(t *testing.T) {
+	c := mustContract(t, testABI, tokenAddress)
+	chain := newFakeChain(t)
+	caller := newTestCaller(t, chain)
+	call := c.NewCall(new(big.Int), "balanceOf", ownerAddress)
+	call.Value = big.NewInt(2)
+	_, err := caller.CallValue(&bind.TransactOpts{Value: big.NewInt(1)}, call)
+	if !errors.Is(err, ErrValueMismatch) {
+		t.Fatalf("expected ErrValueMismatch, got %v", err)
+	}
+	if chain.calls() != 0 {
+		t.Fatal("expected no eth_call")
+	}
+}